@@ -14,29 +14,30 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // IPP constants for direct communication
 const (
-	ippOperationPrintJob = 0x0002
-	ippTagOperation      = 0x01
-	ippTagJob            = 0x02
-	ippTagEnd            = 0x03
-	ippTagCharset        = 0x47
-	ippTagLanguage       = 0x48
-	ippTagUri            = 0x45
-	ippTagName           = 0x42
-	ippTagMimeType       = 0x49
-	ippTagInteger        = 0x21
-	ippContentTypeIPP    = "application/ipp"
+	ippOperationPrintJob             = 0x0002
+	ippOperationGetPrinterAttributes = 0x000B
+	ippTagOperation                  = 0x01
+	ippTagJob                        = 0x02
+	ippTagEnd                        = 0x03
+	ippTagCharset                    = 0x47
+	ippTagLanguage                   = 0x48
+	ippTagUri                        = 0x45
+	ippTagName                       = 0x42
+	ippTagKeyword                    = 0x44
+	ippTagMimeType                   = 0x49
+	ippTagInteger                    = 0x21
+	ippContentTypeIPP                = "application/ipp"
 )
 
 var (
@@ -75,20 +76,16 @@ func (a *App) NewTCPServer() *TCPServer {
 
 	l, err := net.Listen("tcp", addressString)
 	if err != nil {
-		var dialog runtime.MessageDialogOptions
-		dialog.Title = "Error Starting Printer Server"
-		dialog.Message = err.Error()
-		dialog.Type = runtime.ErrorDialog
-		runtime.MessageDialog(a.ctx, dialog)
+		a.ui.Error("Error Starting Printer Server", err.Error())
 		fmt.Println(err)
-		runtime.EventsEmit(a.ctx, "Unblock")
+		a.ui.Emit("Unblock")
 
 		return nil
 	}
 	s.listener = l
 	s.wg.Add(1)
 	Running = true
-	runtime.EventsEmit(a.ctx, "Unblock")
+	a.ui.Emit("Unblock")
 
 	go a.serve()
 
@@ -117,7 +114,7 @@ func (a *App) serve() {
 	}
 }
 func (s *TCPServer) GetStatus(a App) bool {
-	addressString := net.JoinHostPort(CONN_HOST, fmt.Sprintf("%d", a.Settings.PrinterPort))
+	addressString := net.JoinHostPort(CONN_HOST, fmt.Sprintf("%d", int(a.Settings.PrinterPort)))
 	conn, err := net.Dial("tcp", addressString)
 	if err != nil {
 		return false
@@ -146,80 +143,94 @@ func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
 	}
 }
 func (a *App) SendToLabelary(zpl string, width string, height string) error {
+	_, err := a.emulateLabels(zpl)
+	return err
+}
 
-	if zpl == "" {
-		return nil
-	}
-	res, err := a.CallLabelary(zpl, 0, int(a.Settings.PrintWidth), int(a.Settings.PrintHeight))
-	if err != nil {
-		fmt.Println("Error calling Labelary:", err)
-		return nil
-	}
-
-	defer res.Body.Close()
-
-	var imageBytes [][]byte
-	imageByte, err := io.ReadAll(res.Body)
+// emulateLabels renders zpl, shows each label in the frontend and saves it to disk when enabled.
+// The rendered PNGs are returned so callers can keep them with the job.
+func (a *App) emulateLabels(zpl string) ([][]byte, error) {
+	imageBytes, err := a.renderLabels(context.Background(), zpl)
 	if err != nil {
-		panic(err)
-	}
-	if strings.Contains(string(imageByte), "ERROR: Requested 1st label but ZPL generated no labels") {
-		return nil
-	}
-	imageBytes = append(imageBytes, imageByte)
-	countOfLabel := res.Header.Get("x-total-count")
-	if countOfLabel != "" && countOfLabel != "0" && countOfLabel != "1" {
-		labelCounts, err := strconv.Atoi(countOfLabel)
-		if err != nil {
-			fmt.Println("Error converting label count:", err)
-			return nil
-		}
-		for i := 1; i < labelCounts; i++ {
-			time.Sleep(250 * time.Millisecond)
-			res, err := a.CallLabelary(zpl, i, int(a.Settings.PrintWidth), int(a.Settings.PrintHeight))
-			if err != nil {
-				fmt.Println("Error calling Labelary:", err)
-				return nil
-			}
-			defer res.Body.Close()
-			imageByte, err := io.ReadAll(res.Body)
-			if err != nil {
-				panic(err)
-			}
-			if strings.Contains(string(imageByte), "ERROR: Requested 1st label but ZPL generated no labels") {
-				return nil
-			}
-			imageBytes = append(imageBytes, imageByte)
-		}
+		fmt.Fprintln(a.logOutput(), "Error calling Labelary:", err)
+		return imageBytes, err
 	}
+	EmulatorStats.LabelsRendered(len(imageBytes))
+	a.checkGoldens(zpl, imageBytes)
 	for _, v := range imageBytes {
 		base64String := base64.StdEncoding.EncodeToString(v)
 
-		runtime.EventsEmit(a.ctx, "NewPrint", base64String)
+		a.ui.Emit("NewPrint", base64String)
 		if SaveToFile {
-			fname := fmt.Sprintf("%s\\label-print-%d_%d_%d-%d-%d-%d-%d.png", a.Settings.PrintPath, time.Now().Month(), time.Now().Day(), time.Now().Year(), time.Now().Hour(), time.Now().Minute(), time.Now().Second(), time.Now().Nanosecond())
+			fname := filepath.Join(a.Settings.PrintPath, fmt.Sprintf("label-print-%d_%d_%d-%d-%d-%d-%d.png", time.Now().Month(), time.Now().Day(), time.Now().Year(), time.Now().Hour(), time.Now().Minute(), time.Now().Second(), time.Now().Nanosecond()))
 
 			f, err := os.Create(fname)
 			if err != nil {
-				return err
+				return imageBytes, err
 			}
 			defer f.Close()
 
 			_, err = f.Write(v) // Fixed: was writing wrong variable (imageByte instead of v)
 
 			if err != nil {
-				return err
+				return imageBytes, err
 			}
-			fmt.Printf("%s created!", fname)
+			fmt.Fprintf(a.logOutput(), "%s created!", fname)
 		}
 	}
 
-	return nil
+	return imageBytes, nil
+}
+
+// renderLabels converts zpl into one PNG per label using the Labelary API
+func (a *App) renderLabels(ctx context.Context, zpl string) ([][]byte, error) {
+	if zpl == "" {
+		return nil, nil
+	}
+	var imageBytes [][]byte
+	labelCounts := 1
+	for i := 0; i < labelCounts; i++ {
+		if i > 0 {
+			time.Sleep(250 * time.Millisecond)
+		}
+		res, err := a.callLabelary(ctx, zpl, i, a.Settings.PrintWidth, a.Settings.PrintHeight)
+		if err != nil {
+			return imageBytes, err
+		}
+		imageByte, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return imageBytes, fmt.Errorf("failed to read Labelary response: %w", err)
+		}
+		if strings.Contains(string(imageByte), "ERROR: Requested 1st label but ZPL generated no labels") {
+			return imageBytes, nil
+		}
+		if res.StatusCode != http.StatusOK {
+			return imageBytes, fmt.Errorf("labelary returned HTTP %d: %s", res.StatusCode, strings.TrimSpace(string(imageByte)))
+		}
+		imageBytes = append(imageBytes, imageByte)
+
+		if i == 0 {
+			countOfLabel := res.Header.Get("x-total-count")
+			if countOfLabel != "" && countOfLabel != "0" && countOfLabel != "1" {
+				labelCounts, err = strconv.Atoi(countOfLabel)
+				if err != nil {
+					return imageBytes, fmt.Errorf("error converting label count: %w", err)
+				}
+			}
+		}
+	}
+	return imageBytes, nil
 }
 func (a *App) CallLabelary(zpl string, printNumber int, width int, height int) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(context.TODO(), "POST", fmt.Sprintf("http://api.labelary.com/v1/printers/%ddpmm/labels/%dx%d/%d/", a.Settings.PrinterDPI.Dpi, width, height, printNumber), strings.NewReader(zpl))
+	return a.callLabelary(context.TODO(), zpl, printNumber, float64(width), float64(height))
+}
+
+// callLabelary renders one label, giving up when ctx is done
+func (a *App) callLabelary(ctx context.Context, zpl string, printNumber int, width float64, height float64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("http://api.labelary.com/v1/printers/%ddpmm/labels/%gx%g/%d/", a.Settings.PrinterDPI.Dpi, width, height, printNumber), strings.NewReader(zpl))
 	if err != nil {
-		fmt.Printf("client: could not create request: %s\n", err)
+		fmt.Fprintf(a.logOutput(), "client: could not create request: %s\n", err)
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -254,7 +265,7 @@ func writeIPPIntAttribute(buf *bytes.Buffer, tag int8, name string, value int32)
 }
 
 // sendDirectIPPPrintJob sends a raw IPP Print-Job request with PDF data
-func sendDirectIPPPrintJob(host string, port int, endpoint string, useTLS bool, pdfData []byte, documentName string) (int, error) {
+func sendDirectIPPPrintJob(ctx context.Context, out io.Writer, host string, port int, endpoint string, useTLS bool, pdfData []byte, documentName string) (int, error) {
 	proto := "http"
 	if useTLS {
 		proto = "https"
@@ -308,7 +319,7 @@ func sendDirectIPPPrintJob(host string, port int, endpoint string, useTLS bool,
 		},
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(fullRequest))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(fullRequest))
 	if err != nil {
 		return 0, err
 	}
@@ -342,10 +353,18 @@ func sendDirectIPPPrintJob(host string, port int, endpoint string, useTLS bool,
 	}
 
 	// Job submitted successfully
-	fmt.Printf("IPP print job submitted successfully to %s\n", host)
+	fmt.Fprintf(out, "IPP print job submitted successfully to %s\n", host)
 	return 0, nil
 }
 
+// labelPageSize returns the printed label size in inches, swapped for 90 and 270 degree rotations
+func (s *Settings) labelPageSize() (float64, float64) {
+	if int(s.PrintRotation) == 90 || int(s.PrintRotation) == 270 {
+		return s.PrintHeight, s.PrintWidth
+	}
+	return s.PrintWidth, s.PrintHeight
+}
+
 // convertPNGToPDF converts PNG image bytes to PDF bytes
 func convertPNGToPDF(pngBytes []byte, widthInches, heightInches float64) ([]byte, error) {
 	// Decode PNG
@@ -398,7 +417,7 @@ func convertPNGToPDF(pngBytes []byte, widthInches, heightInches float64) ([]byte
 }
 
 // sendPNGDirectlyToIPP tries to send PNG directly via IPP (fallback if printer supports it)
-func sendPNGDirectlyToIPP(host string, port int, endpoint string, useTLS bool, pngData []byte, documentName string) error {
+func sendPNGDirectlyToIPP(ctx context.Context, host string, port int, endpoint string, useTLS bool, pngData []byte, documentName string) error {
 	proto := "http"
 	if useTLS {
 		proto = "https"
@@ -436,7 +455,7 @@ func sendPNGDirectlyToIPP(host string, port int, endpoint string, useTLS bool, p
 		},
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(fullRequest))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(fullRequest))
 	if err != nil {
 		return err
 	}
@@ -470,53 +489,33 @@ func sendPNGDirectlyToIPP(host string, port int, endpoint string, useTLS bool, p
 	return nil
 }
 
-// PrintPNGBytesToLocalPrinter prints a PNG byte array to a specified local printer using Windows built-in tools (no external dependencies)
-func PrintPNGBytesToLocalPrinter(pngBytes []byte, printerName string) error {
-	tmpFile, err := os.CreateTemp("", "temp-*.png")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	tmpFilePath := tmpFile.Name()
-	defer os.Remove(tmpFilePath)
-
-	_, err = tmpFile.Write(pngBytes)
-	if err != nil {
-		tmpFile.Close()
-		return fmt.Errorf("failed to write PDF bytes to temp file: %w", err)
-	}
-	tmpFile.Close()
-
-	cmd := exec.Command("mspaint.exe", "/pt", tmpFilePath, printerName)
-
-	err = cmd.Run()
-	if err != nil {
-		fmt.Printf("Attempted to print PNG using mspaint. Error (if any): %v\n", err)
-		if exitError, ok := err.(*exec.ExitError); ok {
-			fmt.Printf("mspaint stderr: %s\n", string(exitError.Stderr))
-		}
-		return err
-	}
-	fmt.Println("PNG sent to printer via mspaint.")
-	return nil
-}
-
 // ProcessAndSendToPrinter processes a print job for a given printer type and destination
 func (a *App) ProcessAndSendToPrinter(printerType, ipAddress string, port int, zpl string) error {
 	return a.ProcessAndSendToPrinterWithIPP(printerType, ipAddress, port, zpl, "/ipp/print", false)
 }
 
-// ProcessAndSendToPrinterWithIPP processes a print job with full IPP support
+// ProcessAndSendToPrinterWithIPP processes a print job with full IPP support.
+// Local and File printers have no address; use PrintToLocalQueue, WriteToDirectory or SendToPrinter.
 func (a *App) ProcessAndSendToPrinterWithIPP(printerType, ipAddress string, port int, zpl string, ippEndpoint string, useTLS bool) error {
+	return a.sendToAddress(context.Background(), printerType, ipAddress, port, zpl, ippEndpoint, useTLS)
+}
+
+// sendToAddress sends zpl to a Zebra or IPP printer, giving up when ctx is done
+func (a *App) sendToAddress(ctx context.Context, printerType, ipAddress string, port int, zpl string, ippEndpoint string, useTLS bool) error {
 	if printerType == "Zebra" {
 		// Forward the string to port 9100 (raw socket)
 		if port == 0 {
 			port = 9100
 		}
-		conn, err := net.DialTimeout("tcp", net.JoinHostPort(ipAddress, fmt.Sprintf("%d", port)), 10*time.Second)
+		dialer := net.Dialer{Timeout: 10 * time.Second}
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ipAddress, fmt.Sprintf("%d", port)))
 		if err != nil {
 			return fmt.Errorf("failed to connect to Zebra printer: %w", err)
 		}
 		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
 		_, err = conn.Write([]byte(zpl))
 		if err != nil {
 			return fmt.Errorf("failed to send data to Zebra printer: %w", err)
@@ -537,9 +536,9 @@ func (a *App) ProcessAndSendToPrinterWithIPP(printerType, ipAddress string, port
 			ippEndpoint = "/ipp/print"
 		}
 
-		res, err := a.CallLabelary(zpl, 0, int(a.Settings.PrintWidth), int(a.Settings.PrintHeight))
+		res, err := a.callLabelary(ctx, zpl, 0, a.Settings.PrintWidth, a.Settings.PrintHeight)
 		if err != nil {
-			fmt.Println("Error calling Labelary:", err)
+			fmt.Fprintln(a.logOutput(), "Error calling Labelary:", err)
 			return err
 		}
 		defer res.Body.Close()
@@ -559,14 +558,14 @@ func (a *App) ProcessAndSendToPrinterWithIPP(printerType, ipAddress string, port
 		if countOfLabel != "" && countOfLabel != "0" && countOfLabel != "1" {
 			labelCounts, err := strconv.Atoi(countOfLabel)
 			if err != nil {
-				fmt.Println("Error converting label count:", err)
+				fmt.Fprintln(a.logOutput(), "Error converting label count:", err)
 				return nil
 			}
 			for i := 1; i < labelCounts; i++ {
 				time.Sleep(250 * time.Millisecond)
-				res, err := a.CallLabelary(zpl, i, int(a.Settings.PrintWidth), int(a.Settings.PrintHeight))
+				res, err := a.callLabelary(ctx, zpl, i, a.Settings.PrintWidth, a.Settings.PrintHeight)
 				if err != nil {
-					fmt.Println("Error calling Labelary:", err)
+					fmt.Fprintln(a.logOutput(), "Error calling Labelary:", err)
 					continue
 				}
 				defer res.Body.Close()
@@ -588,24 +587,24 @@ func (a *App) ProcessAndSendToPrinterWithIPP(printerType, ipAddress string, port
 			// First try: Convert PNG to PDF and send via IPP
 			pdfBytes, err := convertPNGToPDF(pngBytes, a.Settings.PrintWidth, a.Settings.PrintHeight)
 			if err != nil {
-				fmt.Printf("Failed to convert PNG to PDF: %v, trying PNG fallback\n", err)
+				fmt.Fprintf(a.logOutput(), "Failed to convert PNG to PDF: %v, trying PNG fallback\n", err)
 				// Fallback: Try sending PNG directly
-				err = sendPNGDirectlyToIPP(ipAddress, port, ippEndpoint, useTLS, pngBytes, documentName)
+				err = sendPNGDirectlyToIPP(ctx, ipAddress, port, ippEndpoint, useTLS, pngBytes, documentName)
 				if err != nil {
-					fmt.Printf("IPP PNG fallback also failed: %v\n", err)
+					fmt.Fprintf(a.logOutput(), "IPP PNG fallback also failed: %v\n", err)
 					return err
 				}
 				continue
 			}
 
 			// Send PDF via IPP
-			_, err = sendDirectIPPPrintJob(ipAddress, port, ippEndpoint, useTLS, pdfBytes, documentName)
+			_, err = sendDirectIPPPrintJob(ctx, a.logOutput(), ipAddress, port, ippEndpoint, useTLS, pdfBytes, documentName)
 			if err != nil {
-				fmt.Printf("IPP PDF print failed: %v, trying PNG fallback\n", err)
+				fmt.Fprintf(a.logOutput(), "IPP PDF print failed: %v, trying PNG fallback\n", err)
 				// Fallback: Try sending PNG directly
-				err = sendPNGDirectlyToIPP(ipAddress, port, ippEndpoint, useTLS, pngBytes, documentName)
+				err = sendPNGDirectlyToIPP(ctx, ipAddress, port, ippEndpoint, useTLS, pngBytes, documentName)
 				if err != nil {
-					fmt.Printf("IPP PNG fallback also failed: %v\n", err)
+					fmt.Fprintf(a.logOutput(), "IPP PNG fallback also failed: %v\n", err)
 					return err
 				}
 			}
@@ -615,6 +614,60 @@ func (a *App) ProcessAndSendToPrinterWithIPP(printerType, ipAddress string, port
 	return fmt.Errorf("unsupported printer type: %s", printerType)
 }
 
+// SendToPrinter delivers zpl to a saved printer of any type, including the Local and File
+// types that need more than an address
+func (a *App) SendToPrinter(p Printer, zpl string) error {
+	return a.sendToPrinter(context.Background(), p, zpl)
+}
+
+// sendToPrinter is SendToPrinter giving up when ctx is done
+func (a *App) sendToPrinter(ctx context.Context, p Printer, zpl string) error {
+	switch p.PrinterType {
+	case "Local":
+		return a.printToLocalQueue(ctx, p.QueueName, zpl)
+	case "File":
+		return a.writeToDirectory(ctx, p, zpl)
+	}
+	return a.sendToAddress(ctx, p.PrinterType, p.IPAddress, p.PrinterPort, zpl, p.IPPEndpoint, p.UseTLS)
+}
+
+// PrintToLocalQueue prints zpl on the installed OS printer named queueName
+func (a *App) PrintToLocalQueue(queueName string, zpl string) error {
+	return a.printToLocalQueue(context.Background(), queueName, zpl)
+}
+
+// printToLocalQueue renders zpl and prints each label on an installed OS printer,
+// sized to the label settings
+func (a *App) printToLocalQueue(ctx context.Context, queueName string, zpl string) error {
+	if queueName == "" {
+		return fmt.Errorf("no local print queue configured")
+	}
+	imageBytes, err := a.renderLabels(ctx, zpl)
+	if err != nil {
+		return err
+	}
+	width, height := a.Settings.labelPageSize()
+	for i, pngBytes := range imageBytes {
+		opts := LocalPrintOptions{
+			Format: localPrintFormat,
+			Copies: 1,
+			Media:  localMediaForLabel(width, height),
+			Title:  fmt.Sprintf("ZPL-Label-%d-%d", time.Now().Unix(), i),
+		}
+		data := pngBytes
+		if opts.Format == "pdf" {
+			data, err = convertPNGToPDF(pngBytes, width, height)
+			if err != nil {
+				return err
+			}
+		}
+		if err := PrintToLocalPrinter(ctx, a.logOutput(), data, queueName, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Handles incoming requests.
 func (a *App) handleRequest(conn net.Conn, width string, height string) {
 
@@ -623,6 +676,21 @@ func (a *App) handleRequest(conn net.Conn, width string, height string) {
 		conn.Close()
 	}()
 
+	EmulatorStats.JobStarted()
+	var messageString string
+	var jobErr error
+	defer func() {
+		EmulatorStats.JobFinished(len(messageString), jobErr)
+	}()
+
+	mode, _, _ := printSelection()
+	job := &Job{
+		ReceivedAt:    time.Now(),
+		SourceAddress: conn.RemoteAddr().String(),
+		Listener:      "tcp://" + conn.LocalAddr().String(),
+		Mode:          printModeName(mode),
+	}
+
 	timeoutDuration := 5 * time.Second
 	bufferReader := bufio.NewReader(conn)
 	var lines []string
@@ -634,7 +702,7 @@ func (a *App) handleRequest(conn net.Conn, width string, height string) {
 		if err != nil {
 			lines = append(lines, line)
 			if err != io.EOF {
-				fmt.Println(err)
+				fmt.Fprintln(a.logOutput(), err)
 			}
 			break
 		}
@@ -642,52 +710,84 @@ func (a *App) handleRequest(conn net.Conn, width string, height string) {
 
 	}
 
-	messageString := strings.Join(lines, "")
-	switch PrintMode {
-	case 0:
-		err := a.SendToLabelary(messageString, width, height)
-		if err != nil {
-			fmt.Println(err)
-		}
-	case 1:
-		//ZPL to network Printer
-		a.ProcessAndSendToPrinterWithIPP(SelectedPrinter.PrinterType, SelectedPrinter.IPAddress, SelectedPrinter.PrinterPort, messageString, SelectedPrinter.IPPEndpoint, SelectedPrinter.UseTLS)
-		return
-	case 2:
-		//Printer Relay
-		a.ProcessRelayGroup(messageString)
-		return
-	default:
+	messageString = strings.Join(lines, "")
+	if messageString == "" {
 		return
 	}
+	jobErr = a.processJob(job, messageString, nil)
 }
 
-func (a *App) ProcessRelayGroup(zpl string) {
-	for _, printerID := range LabelRelayGroup.PrinterIDs {
-		printer, err := GetPrinterByID(a.db, printerID)
-		if err != nil {
-			fmt.Println("Error getting printer by ID:", err)
-			continue
+// processJob sends received print data to route, or where the routing rules or the print
+// mode say when route is nil, and records the job. It returns the error of the job, or of
+// the first printer that failed.
+func (a *App) processJob(job *Job, data string, route *RouteDecision) error {
+	job.RawData = []byte(data)
+	if route == nil {
+		route = a.routeJob(job.SourceAddress, job.Listener, data)
+	}
+	job.Mode = routeModeName(route.Action)
+	printer, group, jobErr := a.routeTargets(route)
+	if jobErr != nil {
+		fmt.Fprintln(a.logOutput(), "Error routing job:", jobErr)
+		route.Action = RouteDrop
+	}
+	switch route.Action {
+	case RouteToEmulator:
+		job.Images, jobErr = a.emulateLabels(data)
+		if jobErr != nil {
+			fmt.Fprintln(a.logOutput(), jobErr)
+		}
+	case RouteToPrinter:
+		//ZPL to network Printer
+		jobErr = a.SendToPrinter(printer, data)
+		job.Outcomes = append(job.Outcomes, newJobOutcome(printer, jobErr))
+	case RouteToGroup:
+		//Printer Relay
+		job.Outcomes, jobErr = a.relayToGroup(group, data)
+	}
+	a.recordJob(job, jobErr)
+	if jobErr != nil {
+		return jobErr
+	}
+	for _, o := range job.Outcomes {
+		if o.failed() {
+			return fmt.Errorf("%s: %s", o.PrinterName, o.Error)
 		}
-		a.ProcessAndSendToPrinterWithIPP(printer.PrinterType, printer.IPAddress, printer.PrinterPort, zpl, printer.IPPEndpoint, printer.UseTLS)
 	}
+	return nil
 }
 
-// QueryInstalledPrinters returns a slice of printer names installed on the local Windows machine
-func QueryInstalledPrinters() ([]string, error) {
-	// This function uses PowerShell to get the list of printers
-	cmd := exec.Command("powershell", "-Command", "Get-Printer | Select-Object -ExpandProperty Name")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+// recordJob stores a finished job in the history and tells the frontend about it
+func (a *App) recordJob(job *Job, jobErr error) {
+	job.CompletedAt = time.Now()
+	job.ByteCount = len(job.RawData)
+	job.LabelCount = len(job.Images)
+	job.Status = JobStatusCompleted
+	if job.Mode == RouteDrop {
+		job.Status = JobStatusDropped
+	}
+	if jobErr != nil {
+		job.Status = JobStatusFailed
+		job.Error = jobErr.Error()
+	}
+	for _, o := range job.Outcomes {
+		if o.failed() {
+			job.Status = JobStatusFailed
+		}
+	}
+	if a.db == nil {
+		return
 	}
-	lines := strings.Split(strings.ReplaceAll(string(output), "\r\n", "\n"), "\n")
-	var printers []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed != "" {
-			printers = append(printers, trimmed)
+	if err := AddJob(a.db, job); err != nil {
+		fmt.Fprintln(a.logOutput(), "Error recording job:", err)
+		return
+	}
+	if a.spool != nil {
+		if err := a.spool.Save(job.JobID, job.RawData); err != nil {
+			fmt.Fprintln(a.logOutput(), "Error spooling job:", err)
 		}
 	}
-	return printers, nil
+	IndexJobText(a.db, job)
+	a.ui.Emit("JobRecorded", job.JobID)
 }
+
@@ -3,25 +3,50 @@ package main
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"io"
+	"net"
 	"os"
+	"path/filepath"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
-	"golang.org/x/sys/windows/registry"
 )
 
 // AppVersion is the single source of truth for the application version
 const AppVersion = "2.3.0"
 
-// Registry key name for auto-start
-const autoStartKeyName = "ZPLPrinterEmulator"
-
 // App struct
 // Add db and settings fields to App
 type App struct {
-	ctx      context.Context
-	tcp      *TCPServer
-	db       *sql.DB
-	Settings *Settings
+	ctx        context.Context
+	ui         Notifier
+	tcp        *TCPServer
+	mdns       *MDNSResponder
+	hotFolders []*HotFolder
+	ftp        *FTPServer
+	snmp       *SNMPAgent
+	spool      *JobSpool
+	janitor    *Janitor
+	goldens    *GoldenStore
+
+	configPath    string
+	configWatcher *ConfigWatcher
+	configReport  *ConfigReport
+	db            *sql.DB
+
+	// saved is what the UI stored in the database. Settings is what the emulator runs with:
+	// saved plus the config file and environment overrides in settingOverrides.
+	saved            *Settings
+	settingOverrides []ConfigValue
+	Settings         *Settings
+
+	// chosen is the print mode, printer and relay group selected in the UI. The config file
+	// and environment can override them; chosen comes back when they stop.
+	chosen printChoice
+
+	// output is where the printing code reports progress and errors, stdout when nil. CLI
+	// commands point it at stderr so their own output stays clean.
+	output io.Writer
 }
 
 // NewApp creates a new App application struct
@@ -40,6 +65,28 @@ func NewApp(db *sql.DB) *App {
 	if err != nil {
 		panic(err)
 	}
+	// Initialize routing_rules table at startup
+	err = InitRoutingRulesTable(db)
+	if err != nil {
+		panic(err)
+	}
+	err = InitHotFoldersTable(db)
+	if err != nil {
+		panic(err)
+	}
+	err = InitFTPRoutesTable(db)
+	if err != nil {
+		panic(err)
+	}
+	// Initialize job history tables at startup
+	err = InitJobsTables(db)
+	if err != nil {
+		panic(err)
+	}
+	err = InitJobSearchTable(db)
+	if err != nil {
+		panic(err)
+	}
 	settings, err := LoadSettingsFromDB(db)
 	if err != nil {
 		// If no settings exist, create default
@@ -52,21 +99,53 @@ func NewApp(db *sql.DB) *App {
 			PrintPath:      "",
 			PrinterDPI:     PrinterDPI{Dpi: 8, Description: "8 dpmm (203 dpi)"},
 			DefaultPrinter: 0,
+			SNMPPort:       defaultSNMPPort,
+			SNMPCommunity:  "public",
+			FTPPort:        21,
+			RelayParallel:  defaultRelayParallel,
+			RelayTimeout:   defaultRelayTimeout,
 		}
 		_ = settings.SaveToDB(db)
 	}
-	return &App{db: db, Settings: settings}
+	effective := *settings
+	app := &App{db: db, saved: settings, Settings: &effective}
+	app.ui = &wailsNotifier{app: app}
+	// Spool the raw bytes of recent jobs under the config directory for reprinting
+	if configPath, err := getMyAppConfigPath(); err == nil {
+		app.spool, err = NewJobSpool(filepath.Join(configPath, "spool"), spoolMaxFiles, spoolMaxBytes)
+		if err != nil {
+			println("Error creating job spool:", err.Error())
+		}
+		app.goldens, err = NewGoldenStore(filepath.Join(configPath, "golden"))
+		if err != nil {
+			println("Error creating golden image store:", err.Error())
+		}
+	}
+	return app
+}
+
+// logOutput returns the writer the printing code reports progress and errors to
+func (a *App) logOutput() io.Writer {
+	if a.output == nil {
+		return os.Stdout
+	}
+	return a.output
 }
 
 // startup is called at application startup
 func (a *App) startup(ctx context.Context) {
 	// Perform your setup here
 	a.ctx = ctx
+	a.startConfig(os.Getenv(configPathEnv))
+	a.startJanitor()
 
 }
 
 // domReady is called after front-end resources have been loaded
 func (a *App) domReady(ctx context.Context) {
+	if a.Settings.SNMPEnabled {
+		a.startSNMP()
+	}
 	// Auto-start printer server if setting is enabled
 	if a.Settings.AutoStartServer {
 		a.StartPrinterServer()
@@ -83,6 +162,9 @@ func (a *App) beforeClose(ctx context.Context) (prevent bool) {
 // shutdown is called at application termination
 func (a *App) shutdown(ctx context.Context) {
 	// Perform your teardown here
+	a.stopSNMP()
+	a.stopJanitor()
+	a.stopConfig()
 }
 
 func (a *App) StartPrinterServer() {
@@ -94,17 +176,40 @@ func (a *App) StartPrinterServer() {
 			return
 		}
 		a.tcp.wg.Add(1)
+		if a.Settings.AdvertiseMDNS {
+			a.startMDNS()
+		}
+		a.startHotFolder()
+		a.startFTP()
 	}
 	// a.serve()
 }
 
+// startMDNS advertises the running printer server on the local network
+func (a *App) startMDNS() {
+	responder, err := a.NewMDNSResponder(0)
+	if err != nil {
+		fmt.Println("Error starting mDNS advertisement:", err)
+		a.ui.Error("Error Starting mDNS Advertisement", err.Error())
+		return
+	}
+	a.mdns = responder
+}
+
+// stopMDNS withdraws the mDNS advertisement if one is active
+func (a *App) stopMDNS() {
+	if a.mdns != nil {
+		a.mdns.Stop()
+		a.mdns = nil
+	}
+}
+
 func (a *App) UpdateSave(fileSave bool) {
 	SaveToFile = fileSave
 }
 
 func (a *App) UpdateWidth(width int) {
-	a.Settings.PrintWidth = float64(width)
-	a.Settings.SaveToDB(a.db)
+	a.updateSettings(func(s *Settings) { s.PrintWidth = float64(width) })
 }
 
 func (a *App) GetWidth() int {
@@ -112,8 +217,7 @@ func (a *App) GetWidth() int {
 }
 
 func (a *App) UpdateHeight(height int) {
-	a.Settings.PrintHeight = float64(height)
-	a.Settings.SaveToDB(a.db)
+	a.updateSettings(func(s *Settings) { s.PrintHeight = float64(height) })
 }
 
 func (a *App) GetHeight() int {
@@ -122,16 +226,18 @@ func (a *App) GetHeight() int {
 }
 
 func (a *App) StopPrintServer() {
+	a.stopMDNS()
+	a.stopHotFolder()
+	a.stopFTP()
 	a.tcp.Stop()
-	runtime.EventsEmit(a.ctx, "Unblock")
+	a.ui.Emit("Unblock")
 }
 func (a *App) GetPrinterRunStatus() bool {
 	// active := a.tcp.GetStatus()
 	return Running
 }
 func (a *App) UpdatePrinterDPI(dpi PrinterDPI) {
-	a.Settings.PrinterDPI = dpi
-	a.Settings.SaveToDB(a.db)
+	a.updateSettings(func(s *Settings) { s.PrinterDPI = dpi })
 }
 
 func (a *App) GetPrinterRotation() int {
@@ -139,16 +245,14 @@ func (a *App) GetPrinterRotation() int {
 }
 
 func (a *App) SetPrinterRotation(rotation int) {
-	a.Settings.PrintRotation = float64(rotation)
-	a.Settings.SaveToDB(a.db)
+	a.updateSettings(func(s *Settings) { s.PrintRotation = float64(rotation) })
 }
 
 func (a *App) GetPrinterDPI() PrinterDPI {
 	return a.Settings.PrinterDPI
 }
 func (a *App) UpdatePrinterPort(port int) {
-	a.Settings.PrinterPort = float64(port)
-	a.Settings.SaveToDB(a.db)
+	a.updateSettings(func(s *Settings) { s.PrinterPort = float64(port) })
 	if Running {
 		a.StopPrintServer()
 		a.StartPrinterServer()
@@ -165,14 +269,12 @@ func (a *App) SetPrintDirectory() string {
 	dialog.Title = "Save Print Location"
 
 	path, _ := runtime.OpenDirectoryDialog(a.ctx, dialog)
-	a.Settings.PrintPath = path
-	a.Settings.SaveToDB(a.db)
+	a.updateSettings(func(s *Settings) { s.PrintPath = path })
 
 	return path
 }
 func (a *App) ClearPrintDirectory() {
-	a.Settings.PrintPath = ""
-	a.Settings.SaveToDB(a.db)
+	a.updateSettings(func(s *Settings) { s.PrintPath = "" })
 }
 func (a *App) GetPrintDirectory() string {
 	return a.Settings.PrintPath
@@ -191,12 +293,32 @@ func (a *App) UpdatePrinter(printer Printer) error {
 }
 
 func (a *App) DeletePrinter(printerID int) error {
-	return DeletePrinter(a.db, printerID)
+	if err := DeletePrinter(a.db, printerID); err != nil {
+		return err
+	}
+	// The printer's relay group memberships were removed with it
+	a.refreshSelectedRelayGroup()
+	return nil
 }
 
 // Relay group methods for Wails frontend
 func (a *App) AddRelayGroup(printerIDs []int) error {
-	return AddRelayGroup(a.db, printerIDs)
+	_, err := AddRelayGroup(a.db, RelayGroup{PrinterIDs: printerIDs})
+	return err
+}
+
+// CreateRelayGroup adds a named relay group with ordered members and returns its ID
+func (a *App) CreateRelayGroup(group RelayGroup) (int, error) {
+	return AddRelayGroup(a.db, group)
+}
+
+// UpdateRelayGroup saves a relay group's name, description, strategy and members
+func (a *App) UpdateRelayGroup(group RelayGroup) error {
+	if err := UpdateRelayGroup(a.db, group); err != nil {
+		return err
+	}
+	a.refreshSelectedRelayGroup()
+	return nil
 }
 
 func (a *App) GetRelayGroups() ([]RelayGroup, error) {
@@ -204,23 +326,129 @@ func (a *App) GetRelayGroups() ([]RelayGroup, error) {
 }
 
 func (a *App) DeleteRelayGroup(groupID int) error {
-	return DeleteRelayGroup(a.db, groupID)
+	if err := DeleteRelayGroup(a.db, groupID); err != nil {
+		return err
+	}
+	// Don't keep relaying to a group that is gone
+	a.refreshSelectedRelayGroup()
+	return nil
+}
+
+// refreshSelectedRelayGroup reloads the selected relay group after it may have changed,
+// clearing the selection if the group is gone
+func (a *App) refreshSelectedRelayGroup() {
+	configMu.Lock()
+	defer configMu.Unlock()
+	LabelRelayGroup = a.storedRelayGroup(LabelRelayGroup)
+	a.chosen.group = a.storedRelayGroup(a.chosen.group)
+}
+
+// storedRelayGroup returns the current version of group from the database, or an empty group
+// if it was deleted
+func (a *App) storedRelayGroup(group RelayGroup) RelayGroup {
+	if group.GroupID == 0 {
+		return group
+	}
+	stored, err := GetRelayGroupByID(a.db, group.GroupID)
+	if err != nil {
+		return group
+	}
+	if stored == nil {
+		return RelayGroup{}
+	}
+	return *stored
+}
+
+// refreshSelectedPrinter reloads the selected forward printer, clearing the selection if the
+// printer is gone
+func (a *App) refreshSelectedPrinter() {
+	configMu.Lock()
+	defer configMu.Unlock()
+	SelectedPrinter = a.storedPrinter(SelectedPrinter)
+	a.chosen.printer = a.storedPrinter(a.chosen.printer)
+}
+
+// storedPrinter returns the current version of printer from the database, or an empty
+// printer if it was deleted
+func (a *App) storedPrinter(printer Printer) Printer {
+	if printer.PrinterID == 0 {
+		return printer
+	}
+	stored, err := GetPrinterByID(a.db, printer.PrinterID)
+	if err != nil {
+		return printer
+	}
+	if stored == nil {
+		return Printer{}
+	}
+	return *stored
+}
+
+// SetRelayGroupStrategy sets how a relay group delivers jobs: broadcast, failover,
+// round-robin or least-busy
+func (a *App) SetRelayGroupStrategy(groupID int, strategy string) error {
+	if err := SetRelayGroupStrategy(a.db, groupID, strategy); err != nil {
+		return err
+	}
+	a.refreshSelectedRelayGroup()
+	return nil
+}
+
+// Job history methods for Wails frontend
+func (a *App) GetJobs(offset int, limit int) (*JobPage, error) {
+	return GetJobs(a.db, offset, limit)
+}
+
+// GetJob returns a job with its raw data, reading it from the spool if the history has none
+func (a *App) GetJob(jobID int) (*Job, error) {
+	job, err := GetJobByID(a.db, jobID)
+	if err != nil || job == nil || len(job.RawData) > 0 || a.spool == nil {
+		return job, err
+	}
+	data, err := a.spool.Load(jobID)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	job.RawData = data
+	return job, nil
+}
+
+// SearchJobs finds jobs whose label field data contains the query, with their label images
+func (a *App) SearchJobs(query string, limit int) ([]JobSearchResult, error) {
+	return SearchJobs(a.db, query, limit)
 }
 
 func (a *App) SetPrinterEmulatorMode() {
+	configMu.Lock()
 	PrintMode = 0
+	a.chosen.mode = PrintMode
+	configMu.Unlock()
 }
 func (a *App) SetPrinterRelayMode() {
+	configMu.Lock()
 	PrintMode = 2
+	a.chosen.mode = PrintMode
+	configMu.Unlock()
 }
 func (a *App) SetPrinterZPLToPrinterMode() {
+	configMu.Lock()
 	PrintMode = 1
+	a.chosen.mode = PrintMode
+	configMu.Unlock()
 }
 func (a *App) SelectPrinter(printer Printer) {
+	configMu.Lock()
 	SelectedPrinter = printer
+	a.chosen.printer = printer
+	configMu.Unlock()
 }
 func (a *App) SelectRelayGroup(relayGroup RelayGroup) {
+	configMu.Lock()
 	LabelRelayGroup = relayGroup
+	a.chosen.group = relayGroup
+	configMu.Unlock()
+	// Use the stored group so its strategy and members are current
+	a.refreshSelectedRelayGroup()
 }
 
 // GetVersion returns the application version for frontend display
@@ -228,54 +456,218 @@ func (a *App) GetVersion() string {
 	return AppVersion
 }
 
-// SetAutoStart enables or disables auto-start at Windows login
+// SetAutoStart enables or disables starting the emulator at login
 func (a *App) SetAutoStart(enabled bool) error {
-	key, _, err := registry.CreateKey(registry.CURRENT_USER,
-		`Software\Microsoft\Windows\CurrentVersion\Run`,
-		registry.SET_VALUE|registry.QUERY_VALUE)
+	if !enabled {
+		return disableAutoStart()
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return enableAutoStart(exePath)
+}
+
+// GetAutoStart returns whether auto-start is currently enabled
+func (a *App) GetAutoStart() bool {
+	return autoStartEnabled()
+}
+
+// SetAutoStartServer enables or disables automatic server start when app launches
+func (a *App) SetAutoStartServer(enabled bool) {
+	a.updateSettings(func(s *Settings) { s.AutoStartServer = enabled })
+}
+
+// GetAutoStartServer returns whether auto-start server is enabled
+func (a *App) GetAutoStartServer() bool {
+	return a.Settings.AutoStartServer
+}
+
+// SetAdvertiseMDNS enables or disables advertising the emulator via mDNS/DNS-SD
+func (a *App) SetAdvertiseMDNS(enabled bool) {
+	a.updateSettings(func(s *Settings) { s.AdvertiseMDNS = enabled })
+	if !Running {
+		return
+	}
+	if enabled && a.mdns == nil {
+		a.startMDNS()
+	} else if !enabled {
+		a.stopMDNS()
+	}
+}
+
+// GetAdvertiseMDNS returns whether mDNS advertisement is enabled
+func (a *App) GetAdvertiseMDNS() bool {
+	return a.Settings.AdvertiseMDNS
+}
+
+// startSNMP starts the SNMP agent; it runs independently of the printer server so
+// monitoring tools can see the printer as down while the listener is stopped
+func (a *App) startSNMP() error {
+	agent, err := a.NewSNMPAgent()
 	if err != nil {
+		fmt.Println("Error starting SNMP agent:", err)
 		return err
 	}
-	defer key.Close()
+	a.snmp = agent
+	return nil
+}
+
+func (a *App) stopSNMP() {
+	if a.snmp != nil {
+		a.snmp.Stop()
+		a.snmp = nil
+	}
+}
 
+// SetSNMPEnabled enables or disables the SNMP agent
+func (a *App) SetSNMPEnabled(enabled bool) error {
+	a.updateSettings(func(s *Settings) { s.SNMPEnabled = enabled })
+	a.stopSNMP()
 	if enabled {
-		exePath, err := os.Executable()
-		if err != nil {
-			return err
-		}
-		return key.SetStringValue(autoStartKeyName, exePath)
+		return a.startSNMP()
 	}
+	return nil
+}
 
-	// Delete the registry value if disabling
-	err = key.DeleteValue(autoStartKeyName)
-	// Ignore error if value doesn't exist
-	if err == registry.ErrNotExist {
-		return nil
+// GetSNMPEnabled returns whether the SNMP agent is enabled
+func (a *App) GetSNMPEnabled() bool {
+	return a.Settings.SNMPEnabled
+}
+
+// UpdateSNMPPort changes the SNMP agent port, restarting it if running
+func (a *App) UpdateSNMPPort(port int) error {
+	a.updateSettings(func(s *Settings) { s.SNMPPort = port })
+	if a.snmp != nil {
+		a.stopSNMP()
+		return a.startSNMP()
 	}
-	return err
+	return nil
 }
 
-// GetAutoStart returns whether auto-start is currently enabled
-func (a *App) GetAutoStart() bool {
-	key, err := registry.OpenKey(registry.CURRENT_USER,
-		`Software\Microsoft\Windows\CurrentVersion\Run`,
-		registry.QUERY_VALUE)
+func (a *App) GetSNMPPort() int {
+	return a.Settings.SNMPPort
+}
+
+// UpdateSNMPCommunity changes the read community string, restarting the agent if running
+func (a *App) UpdateSNMPCommunity(community string) error {
+	a.updateSettings(func(s *Settings) { s.SNMPCommunity = community })
+	if a.snmp != nil {
+		a.stopSNMP()
+		return a.startSNMP()
+	}
+	return nil
+}
+
+func (a *App) GetSNMPCommunity() string {
+	return a.Settings.SNMPCommunity
+}
+
+// UpdateSNMPBind changes the address the SNMP agent listens on, restarting it if running.
+// An empty address listens on all interfaces.
+func (a *App) UpdateSNMPBind(address string) error {
+	if address != "" && net.ParseIP(address) == nil {
+		return fmt.Errorf("invalid SNMP bind address %q", address)
+	}
+	a.updateSettings(func(s *Settings) { s.SNMPBind = address })
+	if a.snmp != nil {
+		a.stopSNMP()
+		return a.startSNMP()
+	}
+	return nil
+}
+
+func (a *App) GetSNMPBind() string {
+	return a.Settings.SNMPBind
+}
+
+// GetPrinterStats returns the job counters shared by the listener and SNMP agent
+func (a *App) GetPrinterStats() PrinterStats {
+	return EmulatorStats.Snapshot()
+}
+
+// SetRetentionPolicy updates the limits for saved labels and job history
+func (a *App) SetRetentionPolicy(policy RetentionPolicy) {
+	a.updateSettings(func(s *Settings) { s.Retention = policy })
+}
+
+// GetRetentionPolicy returns the current retention limits
+func (a *App) GetRetentionPolicy() RetentionPolicy {
+	return a.Settings.Retention
+}
+
+// PreviewRetention reports what the retention policy would delete without deleting anything
+func (a *App) PreviewRetention() (*RetentionReport, error) {
+	return a.applyRetention(true)
+}
+
+// ApplyRetention enforces the retention policy immediately
+func (a *App) ApplyRetention() (*RetentionReport, error) {
+	return a.applyRetention(false)
+}
+
+// SaveGoldenFromJob promotes a rendered label from the job history to a golden image
+func (a *App) SaveGoldenFromJob(jobID int, labelIndex int, name string) error {
+	if a.goldens == nil {
+		return fmt.Errorf("golden image store is not available")
+	}
+	images, err := GetJobImages(a.db, jobID)
+	if err != nil {
+		return err
+	}
+	if labelIndex < 0 || labelIndex >= len(images) {
+		return fmt.Errorf("job %d has no label %d", jobID, labelIndex)
+	}
+	return a.goldens.Save(name, images[labelIndex])
+}
+
+// CompareJobWithGolden checks a label from the job history against a golden image
+func (a *App) CompareJobWithGolden(jobID int, labelIndex int, name string) (*CompareResult, error) {
+	if a.goldens == nil {
+		return nil, fmt.Errorf("golden image store is not available")
+	}
+	images, err := GetJobImages(a.db, jobID)
 	if err != nil {
-		return false
+		return nil, err
+	}
+	if labelIndex < 0 || labelIndex >= len(images) {
+		return nil, fmt.Errorf("job %d has no label %d", jobID, labelIndex)
 	}
-	defer key.Close()
+	result := a.goldens.Compare(name, labelIndex, images[labelIndex], a.Settings.GoldenCompare)
+	return &result, nil
+}
 
-	_, _, err = key.GetStringValue(autoStartKeyName)
-	return err == nil
+// ListGoldens returns the names of the stored golden images
+func (a *App) ListGoldens() ([]string, error) {
+	if a.goldens == nil {
+		return nil, nil
+	}
+	return a.goldens.List()
 }
 
-// SetAutoStartServer enables or disables automatic server start when app launches
-func (a *App) SetAutoStartServer(enabled bool) {
-	a.Settings.AutoStartServer = enabled
-	a.Settings.SaveToDB(a.db)
+func (a *App) DeleteGolden(name string) error {
+	if a.goldens == nil {
+		return nil
+	}
+	return a.goldens.Delete(name)
 }
 
-// GetAutoStartServer returns whether auto-start server is enabled
-func (a *App) GetAutoStartServer() bool {
-	return a.Settings.AutoStartServer
+// SetGoldenCompareOptions sets the tolerance used when labels are checked against goldens
+func (a *App) SetGoldenCompareOptions(opts CompareOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	return a.updateSettings(func(s *Settings) { s.GoldenCompare = opts })
+}
+
+func (a *App) GetGoldenCompareOptions() CompareOptions {
+	return a.Settings.GoldenCompare
+}
+
+// GetGoldenResults returns the golden comparisons from the most recent job that had any
+func (a *App) GetGoldenResults() []CompareResult {
+	if a.goldens == nil {
+		return nil
+	}
+	return a.goldens.Latest()
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+)
+
+// LaunchAgent label, also used as the plist file name
+const autoStartLabel = "com.zplprinteremulator.app"
+
+func launchAgentPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", autoStartLabel+".plist"), nil
+}
+
+// enableAutoStart writes a LaunchAgent that opens the emulator at login
+func enableAutoStart(exePath string) error {
+	path, err := launchAgentPath()
+	if err != nil {
+		return err
+	}
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`, autoStartLabel, html.EscapeString(exePath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(plist), 0644)
+}
+
+// disableAutoStart removes the LaunchAgent
+func disableAutoStart() error {
+	path, err := launchAgentPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// autoStartEnabled reports whether the LaunchAgent exists
+func autoStartEnabled() bool {
+	path, err := launchAgentPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// File name used for both the XDG autostart entry and the systemd user unit
+const autoStartName = "zpl-printer-emulator"
+
+// xdgConfigHome returns $XDG_CONFIG_HOME, defaulting to ~/.config
+func xdgConfigHome() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config"), nil
+}
+
+func autoStartDesktopPath() (string, error) {
+	dir, err := xdgConfigHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "autostart", autoStartName+".desktop"), nil
+}
+
+func autoStartUnitPath() (string, error) {
+	dir, err := xdgConfigHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user", autoStartName+".service"), nil
+}
+
+// hasDesktopSession reports whether we are running under a graphical session
+func hasDesktopSession() bool {
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// enableAutoStart writes an XDG autostart entry when running on a desktop, or a systemd
+// user unit that runs the emulator headless when there is no graphical session
+func enableAutoStart(exePath string) error {
+	if hasDesktopSession() {
+		path, err := autoStartDesktopPath()
+		if err != nil {
+			return err
+		}
+		entry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=ZPL Printer Emulator
+Exec=%s
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, desktopExecQuote(exePath))
+		return writeAutoStartFile(path, entry)
+	}
+
+	path, err := autoStartUnitPath()
+	if err != nil {
+		return err
+	}
+	unit := fmt.Sprintf(`[Unit]
+Description=ZPL Printer Emulator
+After=network-online.target
+
+[Service]
+ExecStart=%s --headless
+Restart=on-failure
+
+[Install]
+WantedBy=default.target
+`, desktopExecQuote(exePath))
+	if err := writeAutoStartFile(path, unit); err != nil {
+		return err
+	}
+	out, err := exec.Command("systemctl", "--user", "enable", autoStartName+".service").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("wrote %s but could not enable it: %v: %s", path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// disableAutoStart removes the autostart entry and systemd unit, whichever exist
+func disableAutoStart() error {
+	if path, err := autoStartUnitPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			exec.Command("systemctl", "--user", "disable", autoStartName+".service").Run()
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	path, err := autoStartDesktopPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// autoStartEnabled reports whether an autostart entry or systemd unit exists
+func autoStartEnabled() bool {
+	for _, pathFunc := range []func() (string, error){autoStartDesktopPath, autoStartUnitPath} {
+		if path, err := pathFunc(); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeAutoStartFile(path string, contents string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(contents), 0644)
+}
+
+// desktopExecQuote quotes a path for the Exec key of a desktop entry or a unit's ExecStart
+func desktopExecQuote(path string) string {
+	if !strings.ContainsAny(path, " \t\"\\$`") {
+		return path
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return `"` + r.Replace(path) + `"`
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// Registry key name for auto-start
+const autoStartKeyName = "ZPLPrinterEmulator"
+
+// enableAutoStart adds the executable to the current user's Run key
+func enableAutoStart(exePath string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER,
+		`Software\Microsoft\Windows\CurrentVersion\Run`,
+		registry.SET_VALUE|registry.QUERY_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	return key.SetStringValue(autoStartKeyName, exePath)
+}
+
+// disableAutoStart removes the Run key value
+func disableAutoStart() error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER,
+		`Software\Microsoft\Windows\CurrentVersion\Run`,
+		registry.SET_VALUE|registry.QUERY_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	err = key.DeleteValue(autoStartKeyName)
+	// Ignore error if value doesn't exist
+	if err == registry.ErrNotExist {
+		return nil
+	}
+	return err
+}
+
+// autoStartEnabled reports whether the Run key value exists
+func autoStartEnabled() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER,
+		`Software\Microsoft\Windows\CurrentVersion\Run`,
+		registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer key.Close()
+
+	_, _, err = key.GetStringValue(autoStartKeyName)
+	return err == nil
+}
@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configBundleVersion is the bundle format written by ExportConfigBundle.
+// Bump it when a change to the format can't be read by older versions.
+// Version 2 added relay group names and members; version 1 bundles still import.
+const configBundleVersion = 2
+
+// Import modes
+const (
+	ImportMerge   = "merge"
+	ImportReplace = "replace"
+)
+
+// Bundle file formats. YAML bundles use the same field names as JSON.
+const (
+	BundleJSON = "json"
+	BundleYAML = "yaml"
+)
+
+// ConfigBundle is a portable copy of the settings, printers and relay groups.
+// Relay groups refer to printers by their printerID within the bundle, through members or,
+// in version 1 bundles, printerIDs.
+type ConfigBundle struct {
+	Version     int          `json:"version"`
+	AppVersion  string       `json:"appVersion"`
+	ExportedAt  string       `json:"exportedAt"`
+	Settings    *Settings    `json:"settings,omitempty"`
+	Printers    []Printer    `json:"printers"`
+	RelayGroups []RelayGroup `json:"relayGroups"`
+}
+
+// BundleProblem is one validation error, located by its path in the bundle
+// such as printers[2].ipAddress
+type BundleProblem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// BundleValidationError lists every problem found in a bundle
+type BundleValidationError struct {
+	Problems []BundleProblem `json:"problems"`
+}
+
+func (e *BundleValidationError) Error() string {
+	var lines []string
+	for _, p := range e.Problems {
+		lines = append(lines, fmt.Sprintf("%s: %s", p.Path, p.Message))
+	}
+	return "invalid config bundle:\n  " + strings.Join(lines, "\n  ")
+}
+
+// ImportReport describes what an import changed. PrinterIDs and GroupIDs map bundle IDs to
+// the IDs they were saved under (or already had, for skipped groups).
+type ImportReport struct {
+	Mode            string      `json:"mode"`
+	SettingsApplied bool        `json:"settingsApplied"`
+	PrintersAdded   int         `json:"printersAdded"`
+	PrintersUpdated int         `json:"printersUpdated"`
+	GroupsAdded     int         `json:"groupsAdded"`
+	GroupsUpdated   int         `json:"groupsUpdated"`
+	GroupsSkipped   int         `json:"groupsSkipped"`
+	PrinterIDs      map[int]int `json:"printerIDs"`
+	GroupIDs        map[int]int `json:"groupIDs"`
+}
+
+// ExportConfigBundle reads the current settings, printers and relay groups into a bundle
+func ExportConfigBundle(db *sql.DB) (*ConfigBundle, error) {
+	settings, err := LoadSettingsFromDB(db)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	printers, err := GetPrinters(db)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := GetRelayGroups(db)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigBundle{
+		Version:     configBundleVersion,
+		AppVersion:  AppVersion,
+		ExportedAt:  time.Now().Format(time.RFC3339),
+		Settings:    settings,
+		Printers:    printers,
+		RelayGroups: groups,
+	}, nil
+}
+
+// ParseConfigBundle decodes and validates a JSON or YAML bundle
+func ParseConfigBundle(data []byte) (*ConfigBundle, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] != '{' {
+		// YAML is read through JSON so both formats share the field names and strict checks
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("error reading config bundle: %w", err)
+		}
+		converted, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config bundle: %w", err)
+		}
+		data = converted
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var b ConfigBundle
+	if err := dec.Decode(&b); err != nil {
+		return nil, fmt.Errorf("error reading config bundle: %w", err)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Validate checks the bundle before anything is written, returning a *BundleValidationError
+func (b *ConfigBundle) Validate() error {
+	var problems []BundleProblem
+	add := func(path string, format string, args ...any) {
+		problems = append(problems, BundleProblem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case b.Version == 0:
+		add("version", "missing bundle version")
+	case b.Version > configBundleVersion:
+		add("version", "bundle version %d is newer than this emulator supports (%d)", b.Version, configBundleVersion)
+	}
+
+	if s := b.Settings; s != nil {
+		if s.PrinterPort < 1 || s.PrinterPort > 65535 {
+			add("settings.printerPort", "port %v is out of range", s.PrinterPort)
+		}
+		if s.PrintWidth <= 0 {
+			add("settings.printWidth", "width must be greater than zero")
+		}
+		if s.PrintHeight <= 0 {
+			add("settings.printHeight", "height must be greater than zero")
+		}
+		switch s.PrinterDPI.Dpi {
+		case 6, 8, 12, 24:
+		default:
+			add("settings.printerDPI.value", "%d dpmm is not supported, use 6, 8, 12 or 24", s.PrinterDPI.Dpi)
+		}
+		if s.SNMPPort < 0 || s.SNMPPort > 65535 {
+			add("settings.snmpPort", "port %d is out of range", s.SNMPPort)
+		}
+		if s.SNMPBind != "" && net.ParseIP(s.SNMPBind) == nil {
+			add("settings.snmpBind", "%q is not an IP address", s.SNMPBind)
+		}
+		if s.FTPPort < 0 || s.FTPPort > 65535 {
+			add("settings.ftpPort", "port %d is out of range", s.FTPPort)
+		}
+		if err := s.GoldenCompare.validate(); err != nil {
+			add("settings.goldenCompare", "%v", err)
+		}
+		if s.RelayParallel < 0 {
+			add("settings.relayParallel", "parallelism must not be negative")
+		}
+		if s.RelayTimeout < 0 {
+			add("settings.relayTimeout", "timeout must not be negative")
+		}
+	}
+
+	ids := map[int]bool{}
+	names := map[string]int{}
+	for i, p := range b.Printers {
+		path := fmt.Sprintf("printers[%d]", i)
+		if p.PrinterID <= 0 {
+			add(path+".printerID", "printer ID must be a positive number")
+		} else if ids[p.PrinterID] {
+			add(path+".printerID", "printer ID %d is used more than once", p.PrinterID)
+		}
+		ids[p.PrinterID] = true
+		if strings.TrimSpace(p.PrinterName) == "" {
+			add(path+".printerName", "printer name is required")
+		} else if first, ok := names[strings.ToLower(p.PrinterName)]; ok {
+			add(path+".printerName", "name %q is also used by printers[%d]", p.PrinterName, first)
+		} else {
+			names[strings.ToLower(p.PrinterName)] = i
+		}
+		switch p.PrinterType {
+		case "Zebra", "IPP":
+			if strings.TrimSpace(p.IPAddress) == "" {
+				add(path+".ipAddress", "IP address is required")
+			}
+		case "Local":
+			if strings.TrimSpace(p.QueueName) == "" {
+				add(path+".queueName", "queue name is required for Local printers")
+			}
+		case "File":
+			if strings.TrimSpace(p.OutputDir) == "" {
+				add(path+".outputDir", "output directory is required for File printers")
+			}
+			if err := validateFileTemplate(p.FileTemplate); err != nil {
+				add(path+".fileTemplate", "%v", err)
+			}
+			if _, err := parseFileFormats(p.FileFormats); err != nil {
+				add(path+".fileFormats", "%v", err)
+			}
+		default:
+			add(path+".printerType", "unknown printer type %q", p.PrinterType)
+		}
+		if p.PrinterPort < 0 || p.PrinterPort > 65535 {
+			add(path+".printerPort", "port %d is out of range", p.PrinterPort)
+		}
+	}
+
+	groupIDs := map[int]bool{}
+	for i, g := range b.RelayGroups {
+		path := fmt.Sprintf("relayGroups[%d]", i)
+		if g.GroupID != 0 && groupIDs[g.GroupID] {
+			add(path+".groupID", "group ID %d is used more than once", g.GroupID)
+		}
+		groupIDs[g.GroupID] = true
+		field := "members"
+		if len(g.Members) == 0 {
+			field = "printerIDs"
+		}
+		members := g.memberList()
+		if len(members) == 0 {
+			add(path+"."+field, "relay group has no printers")
+		}
+		if err := validateRelayStrategy(g.Strategy); err != nil {
+			add(path+".strategy", "%s", err.Error())
+		}
+		inGroup := map[int]bool{}
+		for j, m := range members {
+			memberPath := fmt.Sprintf("%s.%s[%d]", path, field, j)
+			switch {
+			case !ids[m.PrinterID]:
+				add(memberPath, "printer %d is not in the bundle", m.PrinterID)
+			case inGroup[m.PrinterID]:
+				add(memberPath, "printer %d is in the group more than once", m.PrinterID)
+			}
+			inGroup[m.PrinterID] = true
+		}
+	}
+
+	if len(problems) > 0 {
+		return &BundleValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ImportConfigBundle writes a validated bundle to the database. In merge mode printers are
+// matched to existing ones by name and updated; in replace mode all printers and relay
+// groups are removed first. Relay group membership is remapped to the saved printer IDs.
+func ImportConfigBundle(db *sql.DB, b *ConfigBundle, mode string) (*ImportReport, error) {
+	if mode != ImportMerge && mode != ImportReplace {
+		return nil, fmt.Errorf("unknown import mode %q, use %s or %s", mode, ImportMerge, ImportReplace)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	report := &ImportReport{Mode: mode, PrinterIDs: map[int]int{}, GroupIDs: map[int]int{}}
+
+	// In merge mode printers and named groups are matched by name, and an unnamed group that
+	// already exists with the same members is not added twice
+	existing := map[string]int{}
+	existingGroups := map[string]int{}
+	seen := map[string]int{}
+	if mode == ImportMerge {
+		printers, err := GetPrinters(db)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range printers {
+			existing[strings.ToLower(p.PrinterName)] = p.PrinterID
+		}
+		groups, err := GetRelayGroups(db)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			seen[relayGroupKey(g.Strategy, g.PrinterIDs)] = g.GroupID
+			if g.Name != "" {
+				existingGroups[strings.ToLower(g.Name)] = g.GroupID
+			}
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if mode == ImportReplace {
+		for _, table := range []string{"relay_group_members", "relay_groups", "printers"} {
+			if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+				println("Error clearing", table+":", err.Error())
+				return nil, err
+			}
+		}
+	}
+
+	for _, p := range b.Printers {
+		if p.IPPEndpoint == "" {
+			p.IPPEndpoint = "/ipp/print"
+		}
+		useTLSInt := 0
+		if p.UseTLS {
+			useTLSInt = 1
+		}
+		if id, ok := existing[strings.ToLower(p.PrinterName)]; ok {
+			_, err = tx.Exec(`
+				UPDATE printers SET printerName=?, ipAddress=?, printerPort=?, printerType=?, ippEndpoint=?, useTLS=?, queueName=?, outputDir=?, fileTemplate=?, fileFormats=? WHERE printerID=?
+			`, p.PrinterName, p.IPAddress, p.PrinterPort, p.PrinterType, p.IPPEndpoint, useTLSInt, p.QueueName, p.OutputDir, p.FileTemplate, p.FileFormats, id)
+			if err != nil {
+				return nil, fmt.Errorf("error updating printer %q: %w", p.PrinterName, err)
+			}
+			report.PrinterIDs[p.PrinterID] = id
+			report.PrintersUpdated++
+			continue
+		}
+		res, err := tx.Exec(`
+			INSERT INTO printers (printerName, ipAddress, printerPort, printerType, ippEndpoint, useTLS, queueName, outputDir, fileTemplate, fileFormats)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, p.PrinterName, p.IPAddress, p.PrinterPort, p.PrinterType, p.IPPEndpoint, useTLSInt, p.QueueName, p.OutputDir, p.FileTemplate, p.FileFormats)
+		if err != nil {
+			return nil, fmt.Errorf("error adding printer %q: %w", p.PrinterName, err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		report.PrinterIDs[p.PrinterID] = int(id)
+		report.PrintersAdded++
+	}
+
+	for _, g := range b.RelayGroups {
+		members := append([]RelayGroupMember(nil), g.memberList()...)
+		var ids []int
+		for i := range members {
+			members[i].PrinterID = report.PrinterIDs[members[i].PrinterID]
+			if members[i].Enabled {
+				ids = append(ids, members[i].PrinterID)
+			}
+		}
+		g.Members = members
+		if g.Strategy == "" {
+			g.Strategy = RelayBroadcast
+		}
+
+		if id, ok := existingGroups[strings.ToLower(g.Name)]; ok && g.Name != "" {
+			_, err := tx.Exec(`UPDATE relay_groups SET name=?, description=?, strategy=? WHERE groupID=?`, g.Name, g.Description, g.Strategy, id)
+			if err == nil {
+				err = replaceRelayGroupMembers(tx, id, members)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error updating relay group %q: %w", g.Name, err)
+			}
+			report.GroupIDs[g.GroupID] = id
+			report.GroupsUpdated++
+			continue
+		}
+		if id, ok := seen[relayGroupKey(g.Strategy, ids)]; ok && g.Name == "" {
+			report.GroupIDs[g.GroupID] = id
+			report.GroupsSkipped++
+			continue
+		}
+		id, err := insertRelayGroup(tx, g)
+		if err != nil {
+			return nil, fmt.Errorf("error adding relay group: %w", err)
+		}
+		seen[relayGroupKey(g.Strategy, ids)] = id
+		report.GroupIDs[g.GroupID] = id
+		report.GroupsAdded++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if b.Settings != nil {
+		s := *b.Settings
+		s.SettingID = 1
+		// Bundles never carry the FTP password, so keep the one already set
+		s.FTPPassword = ""
+		if current, err := LoadSettingsFromDB(db); err == nil {
+			s.FTPPassword = current.FTPPassword
+		}
+		if id, ok := report.PrinterIDs[s.DefaultPrinter]; ok {
+			s.DefaultPrinter = id
+		} else if mode == ImportReplace {
+			s.DefaultPrinter = 0
+		}
+		if err := s.SaveToDB(db); err != nil {
+			return report, fmt.Errorf("printers and relay groups were imported but the settings could not be saved: %w", err)
+		}
+		report.SettingsApplied = true
+	}
+	return report, nil
+}
+
+func relayGroupKey(strategy string, ids []int) string {
+	return strategy + fmt.Sprint(ids)
+}
+
+// Encode writes the bundle as JSON or YAML
+func (b *ConfigBundle) Encode(format string) ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil || format == BundleJSON {
+		return data, err
+	}
+	if format != BundleYAML {
+		return nil, fmt.Errorf("unknown bundle format %q, use %s or %s", format, BundleJSON, BundleYAML)
+	}
+	// JSON is valid YAML, so reading it back as a node tree keeps the field names and order;
+	// clearing the styles turns the flow mappings and quoted strings into plain block YAML
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var plain func(n *yaml.Node)
+	plain = func(n *yaml.Node) {
+		n.Style = 0
+		for _, c := range n.Content {
+			plain(c)
+		}
+	}
+	plain(&doc)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	enc.Close()
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// ExportConfig returns the settings, printers and relay groups as a JSON bundle
+func (a *App) ExportConfig() (string, error) {
+	return a.ExportConfigAs(BundleJSON)
+}
+
+// ExportConfigAs returns the settings, printers and relay groups as a JSON or YAML bundle
+func (a *App) ExportConfigAs(format string) (string, error) {
+	b, err := ExportConfigBundle(a.db)
+	if err != nil {
+		return "", err
+	}
+	data, err := b.Encode(format)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ImportConfig imports a JSON or YAML bundle in merge or replace mode and reloads the settings
+func (a *App) ImportConfig(data string, mode string) (*ImportReport, error) {
+	b, err := ParseConfigBundle([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	report, err := ImportConfigBundle(a.db, b, mode)
+	if report != nil {
+		// A replace import deletes every printer and group, and a merge can change them
+		a.refreshSelectedPrinter()
+		a.refreshSelectedRelayGroup()
+	}
+	if err != nil {
+		return report, err
+	}
+	a.reloadSettings()
+	return report, nil
+}
@@ -0,0 +1,508 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cliCommands are the subcommands that run without starting the GUI
+var cliCommands = map[string]func(args []string, stdout io.Writer, stderr io.Writer) int{
+	"diff":   runDiffCommand,
+	"export": runExportCommand,
+	"import": runImportCommand,
+	"render": runRenderCommand,
+	"send":   runSendCommand,
+}
+
+// runCLI runs a subcommand, or headless mode for --headless, if the first argument asks for
+// one. It reports whether the arguments were handled and the exit code to use.
+func runCLI(args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	if args[0] == "--headless" || args[0] == "-headless" {
+		return runHeadless(args[1:], os.Stdout, os.Stderr), true
+	}
+	command, ok := cliCommands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	return command(args[1:], os.Stdout, os.Stderr), true
+}
+
+// openAppDatabase opens the emulator's SQLite database in the user config directory
+func openAppDatabase() (*DB, error) {
+	configPath, err := getMyAppConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("error getting application config path: %w", err)
+	}
+	db, err := ConnectSQLLite3(filepath.Join(configPath, "printEmulator.db"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to database: %w", err)
+	}
+	return db, nil
+}
+
+// newCLIApp creates the App for a subcommand. Subcommands write their results to stdout, so
+// the printing code's progress and errors go to stderr instead.
+func newCLIApp(db *sql.DB, stderr io.Writer) *App {
+	app := NewApp(db)
+	app.ui = newLogNotifier(stderr)
+	app.output = stderr
+	return app
+}
+
+// runDiffCommand compares two ZPL sources, each a file path or a stored job ID.
+// It exits 0 when they match, 1 when they differ and 2 on errors, like diff(1).
+func runDiffCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOutput := fs.Bool("json", false, "print the diff as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: Printer_Emulator diff [--json] <old file|job ID> <new file|job ID>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	var app *App
+	load := func(source string) ([]byte, error) {
+		if _, err := os.Stat(source); err == nil {
+			return os.ReadFile(source)
+		}
+		jobID, err := strconv.Atoi(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s is neither a file nor a job ID", source)
+		}
+		if app == nil {
+			db, err := openAppDatabase()
+			if err != nil {
+				return nil, err
+			}
+			app = newCLIApp(db.SQL, stderr)
+		}
+		return app.jobRawData(jobID)
+	}
+	oldData, err := load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 2
+	}
+	newData, err := load(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 2
+	}
+
+	diff := DiffZPL(string(oldData), string(newData))
+	if *jsonOutput {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return 2
+		}
+	} else {
+		fmt.Fprint(stdout, FormatZPLDiff(diff))
+	}
+	if !diff.Identical {
+		return 1
+	}
+	return 0
+}
+
+// parseInterleaved parses flags that may appear before, after or between positional
+// arguments and returns the positional arguments
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseLabelSize reads a WIDTHxHEIGHT size in inches such as 4x6 or 2.25x1.25
+func parseLabelSize(size string) (float64, float64, error) {
+	w, h, ok := strings.Cut(strings.ToLower(size), "x")
+	width, errW := strconv.ParseFloat(strings.TrimSpace(w), 64)
+	height, errH := strconv.ParseFloat(strings.TrimSpace(h), 64)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %q, expected WIDTHxHEIGHT in inches such as 4x6 or 2.25x1.25", size)
+	}
+	return width, height, nil
+}
+
+// runRenderCommand renders ZPL files through Labelary and writes one PNG or PDF per label.
+// It exits 1 if any file fails to render and 2 on usage errors.
+func runRenderCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dpi := fs.Int("dpi", 8, "print density in dots per mm (6, 8, 12 or 24)")
+	size := fs.String("size", "4x6", "label size in inches, WIDTHxHEIGHT")
+	rotate := fs.Int("rotate", 0, "rotation in degrees (0, 90, 180 or 270)")
+	out := fs.String("out", ".", "directory to write the rendered labels to")
+	format := fs.String("format", "png", "output format, png or pdf")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: Printer_Emulator render [flags] <file.zpl|-> ...")
+		fs.PrintDefaults()
+	}
+	files, err := parseInterleaved(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return 2
+	}
+	width, height, err := parseLabelSize(*size)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 2
+	}
+	switch *dpi {
+	case 6, 8, 12, 24:
+	default:
+		fmt.Fprintf(stderr, "Error: invalid dpi %d, use 6, 8, 12 or 24 dots per mm\n", *dpi)
+		return 2
+	}
+	switch *rotate {
+	case 0, 90, 180, 270:
+	default:
+		fmt.Fprintf(stderr, "Error: invalid rotation %d, use 0, 90, 180 or 270\n", *rotate)
+		return 2
+	}
+	*format = strings.ToLower(*format)
+	if *format != "png" && *format != "pdf" {
+		fmt.Fprintf(stderr, "Error: invalid format %q, use png or pdf\n", *format)
+		return 2
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		fmt.Fprintln(stderr, "Error creating output directory:", err)
+		return 1
+	}
+
+	// Rendering only needs the label settings, not the database or the GUI
+	app := &App{Settings: &Settings{
+		PrintWidth:    width,
+		PrintHeight:   height,
+		PrintRotation: float64(*rotate),
+		PrinterDPI:    PrinterDPI{Dpi: *dpi},
+	}, output: stderr}
+	pageWidth, pageHeight := app.Settings.labelPageSize()
+
+	exitCode := 0
+	for i, file := range files {
+		if i > 0 {
+			time.Sleep(250 * time.Millisecond) // Stay under Labelary's rate limit
+		}
+		var data []byte
+		var name string
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+			name = "stdin"
+		} else {
+			data, err = os.ReadFile(file)
+			name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "Error reading", file+":", err)
+			exitCode = 1
+			continue
+		}
+		images, err := app.renderLabels(context.Background(), string(data))
+		if err != nil {
+			fmt.Fprintln(stderr, "Error rendering", file+":", err)
+			exitCode = 1
+		} else if len(images) == 0 {
+			fmt.Fprintln(stderr, "Error rendering", file+": ZPL generated no labels")
+			exitCode = 1
+		}
+		for n, img := range images {
+			output := img
+			if *format == "pdf" {
+				output, err = convertPNGToPDF(img, pageWidth, pageHeight)
+				if err != nil {
+					fmt.Fprintln(stderr, "Error converting", file, "label", n+1, "to PDF:", err)
+					exitCode = 1
+					continue
+				}
+			}
+			path := filepath.Join(*out, fmt.Sprintf("%s-%d.%s", name, n+1, *format))
+			if err := os.WriteFile(path, output, 0644); err != nil {
+				fmt.Fprintln(stderr, "Error writing", path+":", err)
+				exitCode = 1
+				continue
+			}
+			fmt.Fprintln(stdout, path)
+		}
+	}
+	return exitCode
+}
+
+// findPrinter looks up a saved printer by ID or, failing that, by name (case-insensitive)
+func findPrinter(db *sql.DB, ref string) (*Printer, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		printer, err := GetPrinterByID(db, id)
+		if err != nil || printer != nil {
+			return printer, err
+		}
+	}
+	printers, err := GetPrinters(db)
+	if err != nil {
+		return nil, err
+	}
+	var found *Printer
+	for i := range printers {
+		if strings.EqualFold(printers[i].PrinterName, ref) {
+			if found != nil {
+				return nil, fmt.Errorf("more than one printer is named %q, use its ID instead", ref)
+			}
+			found = &printers[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("printer %q not found", ref)
+	}
+	return found, nil
+}
+
+// findRelayGroup looks up a relay group by ID or, failing that, by name (case-insensitive)
+func findRelayGroup(db *sql.DB, ref string) (*RelayGroup, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		group, err := GetRelayGroupByID(db, id)
+		if err != nil || group != nil {
+			return group, err
+		}
+	}
+	groups, err := GetRelayGroups(db)
+	if err != nil {
+		return nil, err
+	}
+	var found *RelayGroup
+	for i := range groups {
+		if strings.EqualFold(groups[i].Name, ref) {
+			if found != nil {
+				return nil, fmt.Errorf("more than one relay group is named %q, use its ID instead", ref)
+			}
+			found = &groups[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("relay group %q not found", ref)
+	}
+	return found, nil
+}
+
+// sendResult is the outcome of sending one file, as printed by the send command
+type sendResult struct {
+	File     string       `json:"file"`
+	Outcomes []JobOutcome `json:"outcomes"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// runSendCommand sends files to a saved printer or relay group.
+// It exits 1 if any delivery fails and 2 on usage errors.
+func runSendCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("send", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	printerRef := fs.String("printer", "", "name or ID of the saved printer to send to")
+	groupRef := fs.String("group", "", "name or ID of the relay group to send to")
+	jsonOutput := fs.Bool("json", false, "print the results as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: Printer_Emulator send (--printer NAME|ID | --group NAME|ID) [--json] <file|-> ...")
+		fs.PrintDefaults()
+	}
+	files, err := parseInterleaved(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 || (*printerRef == "") == (*groupRef == "") {
+		fs.Usage()
+		return 2
+	}
+
+	db, err := openAppDatabase()
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	defer db.SQL.Close()
+	app := newCLIApp(db.SQL, stderr)
+
+	var printer *Printer
+	var group *RelayGroup
+	if *printerRef != "" {
+		printer, err = findPrinter(db.SQL, *printerRef)
+	} else {
+		group, err = findRelayGroup(db.SQL, *groupRef)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+
+	exitCode := 0
+	var results []sendResult
+	for _, file := range files {
+		result := sendResult{File: file}
+		var data []byte
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case printer != nil:
+			_, timeout := app.Settings.relayLimits()
+			result.Outcomes = []JobOutcome{app.sendWithTimeout(*printer, string(data), timeout)}
+		default:
+			result.Outcomes, err = app.relayToGroup(*group, string(data))
+			if err != nil {
+				result.Error = err.Error()
+			}
+		}
+		if result.Error != "" {
+			exitCode = 1
+		}
+		for _, o := range result.Outcomes {
+			if o.failed() {
+				exitCode = 1
+			}
+		}
+		results = append(results, result)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return 1
+		}
+		return exitCode
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(stdout, "%s: FAILED %s\n", r.File, r.Error)
+			continue
+		}
+		for _, o := range r.Outcomes {
+			switch {
+			case o.Success:
+				fmt.Fprintf(stdout, "%s: OK %s (printer %d)\n", r.File, o.PrinterName, o.PrinterID)
+			case o.FailedOver:
+				fmt.Fprintf(stdout, "%s: FAILED OVER %s (printer %d): %s\n", r.File, o.PrinterName, o.PrinterID, o.Error)
+			default:
+				fmt.Fprintf(stdout, "%s: FAILED %s (printer %d): %s\n", r.File, o.PrinterName, o.PrinterID, o.Error)
+			}
+		}
+	}
+	return exitCode
+}
+
+// runExportCommand writes the settings, printers and relay groups as a JSON or YAML bundle
+func runExportCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("out", "-", "file to write the bundle to, - for stdout")
+	format := fs.String("format", "", "bundle format, json or yaml (default from the --out extension, else json)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format == "" {
+		*format = BundleJSON
+		if ext := strings.ToLower(filepath.Ext(*out)); ext == ".yaml" || ext == ".yml" {
+			*format = BundleYAML
+		}
+	}
+
+	db, err := openAppDatabase()
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	defer db.SQL.Close()
+	app := newCLIApp(db.SQL, stderr)
+	data, err := app.ExportConfigAs(*format)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error exporting config:", err)
+		return 1
+	}
+	if *out == "-" {
+		fmt.Fprintln(stdout, data)
+		return 0
+	}
+	if err := os.WriteFile(*out, []byte(data+"\n"), 0644); err != nil {
+		fmt.Fprintln(stderr, "Error writing", *out+":", err)
+		return 1
+	}
+	return 0
+}
+
+// runImportCommand imports a JSON or YAML bundle written by export
+func runImportCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	mode := fs.String("mode", ImportMerge, "merge with the existing config or replace it (merge, replace)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: Printer_Emulator import [--mode merge|replace] <bundle.json|bundle.yaml|->")
+		fs.PrintDefaults()
+	}
+	files, err := parseInterleaved(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return 2
+	}
+	var data []byte
+	if files[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(files[0])
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+
+	db, err := openAppDatabase()
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	defer db.SQL.Close()
+	app := newCLIApp(db.SQL, stderr)
+	report, err := app.ImportConfig(string(data), *mode)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Imported (%s): %d printers added, %d updated, %d relay groups added, %d updated, %d skipped\n",
+		report.Mode, report.PrintersAdded, report.PrintersUpdated, report.GroupsAdded, report.GroupsUpdated, report.GroupsSkipped)
+	return 0
+}
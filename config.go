@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables read on top of the database settings and the config file
+const (
+	configEnvPrefix = "PRINTER_EMULATOR_"
+	configPathEnv   = configEnvPrefix + "CONFIG"
+)
+
+// How often the config file is checked for changes
+const configPollInterval = 2 * time.Second
+
+// Where a configured value came from, lowest priority first
+const (
+	ConfigSourceDefault  = "default"
+	ConfigSourceDatabase = "database"
+	ConfigSourceFile     = "file"
+	ConfigSourceEnv      = "env"
+)
+
+// configKeys are the values that can be set from the config file or environment, in report
+// order. The environment variable is the key in upper snake case, e.g. PRINTER_EMULATOR_SAVE_PATH.
+var configKeys = []string{"port", "bind", "dpi", "size", "rotation", "mode", "printer", "relayGroup", "savePath"}
+
+// configSettingKeys are the config keys stored in Settings; the others set the print mode
+var configSettingKeys = map[string]bool{"port": true, "dpi": true, "size": true, "rotation": true, "savePath": true}
+
+// configMu guards the settings, the print mode globals and the config report, which the
+// config file watcher changes while the UI and the listeners use them
+var configMu sync.Mutex
+
+// ConfigFile is the declarative config file. Every field is optional; printers and relay
+// groups are merged into the database like an imported bundle.
+type ConfigFile struct {
+	Port        *int         `json:"port"`
+	Bind        *string      `json:"bind"`
+	DPI         *int         `json:"dpi"`
+	Size        *string      `json:"size"`       // WIDTHxHEIGHT in inches
+	Rotation    *int         `json:"rotation"`   // degrees
+	Mode        *string      `json:"mode"`       // emulate, forward or relay
+	Printer     *string      `json:"printer"`    // forward target, by name or ID
+	RelayGroup  *int         `json:"relayGroup"` // ID in the file; the environment may also give a name
+	SavePath    *string      `json:"savePath"`
+	Printers    []Printer    `json:"printers"`
+	RelayGroups []RelayGroup `json:"relayGroups"`
+}
+
+// ConfigValue is the effective value of one key and the source that won
+type ConfigValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ConfigReport shows how the running configuration was assembled
+type ConfigReport struct {
+	Path     string        `json:"path"`
+	LoadedAt string        `json:"loadedAt"`
+	Values   []ConfigValue `json:"values"`
+	Error    string        `json:"error"`
+}
+
+// ConfigWatcher reloads the config file when it changes
+type ConfigWatcher struct {
+	quit chan any
+	done chan any
+}
+
+func configEnvName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return configEnvPrefix + strings.ToUpper(b.String())
+}
+
+// ReadConfigFile parses a JSON config file, rejecting unknown keys
+func ReadConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var c ConfigFile
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// values returns the keys the file sets, as strings
+func (c *ConfigFile) values() map[string]string {
+	v := map[string]string{}
+	if c.Port != nil {
+		v["port"] = strconv.Itoa(*c.Port)
+	}
+	if c.Bind != nil {
+		v["bind"] = *c.Bind
+	}
+	if c.DPI != nil {
+		v["dpi"] = strconv.Itoa(*c.DPI)
+	}
+	if c.Size != nil {
+		v["size"] = *c.Size
+	}
+	if c.Rotation != nil {
+		v["rotation"] = strconv.Itoa(*c.Rotation)
+	}
+	if c.Mode != nil {
+		v["mode"] = *c.Mode
+	}
+	if c.Printer != nil {
+		v["printer"] = *c.Printer
+	}
+	if c.RelayGroup != nil {
+		v["relayGroup"] = strconv.Itoa(*c.RelayGroup)
+	}
+	if c.SavePath != nil {
+		v["savePath"] = *c.SavePath
+	}
+	return v
+}
+
+// applyConfig layers the config file and environment over the database settings and applies
+// the result. It is safe to call again when the file changes. The database keeps only the
+// settings saved from the UI; the overrides are applied to a separate copy.
+func (a *App) applyConfig(path string) *ConfigReport {
+	report := &ConfigReport{Path: path, LoadedAt: time.Now().Format(time.RFC3339)}
+
+	// IDs in the file refer to its own printers and relay groups until they are merged in
+	var file *ConfigFile
+	var groupIDs map[int]int
+	if path != "" {
+		var err error
+		file, err = ReadConfigFile(path)
+		if err != nil {
+			report.Error = err.Error()
+		} else if len(file.Printers) > 0 || len(file.RelayGroups) > 0 {
+			bundle := &ConfigBundle{Version: configBundleVersion, Printers: file.Printers, RelayGroups: file.RelayGroups}
+			imported, err := ImportConfigBundle(a.db, bundle, ImportMerge)
+			if err != nil {
+				report.Error = err.Error()
+			} else {
+				groupIDs = imported.GroupIDs
+			}
+		}
+	}
+
+	configMu.Lock()
+	saved, err := LoadSettingsFromDB(a.db)
+	if err != nil {
+		saved = a.saved
+	}
+	values := map[string]ConfigValue{
+		"port":       {Value: strconv.Itoa(int(saved.PrinterPort)), Source: ConfigSourceDatabase},
+		"bind":       {Value: "127.0.0.1", Source: ConfigSourceDefault},
+		"dpi":        {Value: strconv.Itoa(saved.PrinterDPI.Dpi), Source: ConfigSourceDatabase},
+		"size":       {Value: fmt.Sprintf("%gx%g", saved.PrintWidth, saved.PrintHeight), Source: ConfigSourceDatabase},
+		"rotation":   {Value: strconv.Itoa(int(saved.PrintRotation)), Source: ConfigSourceDatabase},
+		"mode":       {Value: printModeName(a.chosen.mode), Source: ConfigSourceDefault},
+		"printer":    {Source: ConfigSourceDefault},
+		"relayGroup": {Source: ConfigSourceDefault},
+		"savePath":   {Value: saved.PrintPath, Source: ConfigSourceDatabase},
+	}
+	if file != nil {
+		for k, v := range file.values() {
+			values[k] = ConfigValue{Value: v, Source: ConfigSourceFile}
+		}
+	}
+	for _, k := range configKeys {
+		if v, ok := os.LookupEnv(configEnvName(k)); ok {
+			values[k] = ConfigValue{Value: v, Source: ConfigSourceEnv}
+		}
+	}
+
+	oldPort, oldBind := int(a.Settings.PrinterPort), CONN_HOST
+	settings := *saved
+	// Start from the UI's selection so a key removed from the file stops overriding it
+	PrintMode, SelectedPrinter, LabelRelayGroup = a.chosen.mode, a.chosen.printer, a.chosen.group
+	var overrides []ConfigValue
+	for _, k := range configKeys {
+		v := values[k]
+		v.Key = k
+		if err := a.applyConfigValue(&settings, k, v, groupIDs); err != nil {
+			v.Error = err.Error()
+		} else if configSettingKeys[k] && (v.Source == ConfigSourceFile || v.Source == ConfigSourceEnv) {
+			overrides = append(overrides, v)
+			if k == "savePath" {
+				SaveToFile = v.Value != ""
+			}
+		}
+		report.Values = append(report.Values, v)
+	}
+	a.saved = saved
+	a.settingOverrides = overrides
+	a.Settings = &settings
+	moved := int(settings.PrinterPort) != oldPort || CONN_HOST != oldBind
+	configMu.Unlock()
+
+	if Running && moved {
+		fmt.Printf("Listener moved to %s:%d, restarting printer server\n", CONN_HOST, int(settings.PrinterPort))
+		a.StopPrintServer()
+		a.StartPrinterServer()
+	}
+	return report
+}
+
+// applyConfigValue parses one value and applies it to settings or the print mode globals.
+// Values left at their default source are not applied so the UI's choices stand.
+func (a *App) applyConfigValue(settings *Settings, key string, v ConfigValue, groupIDs map[int]int) error {
+	if v.Source == ConfigSourceDefault && key != "bind" {
+		return nil
+	}
+	switch key {
+	case "port":
+		port, err := strconv.Atoi(v.Value)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q", v.Value)
+		}
+		settings.PrinterPort = float64(port)
+	case "bind":
+		CONN_HOST = v.Value
+	case "dpi":
+		dpi, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return fmt.Errorf("invalid dpi %q", v.Value)
+		}
+		switch dpi {
+		case 6, 8, 12, 24:
+			settings.PrinterDPI = PrinterDPI{Dpi: dpi, Description: fmt.Sprintf("%d dpmm (%d dpi)", dpi, int(float64(dpi)*25.4+0.5))}
+		default:
+			return fmt.Errorf("invalid dpi %d, use 6, 8, 12 or 24 dots per mm", dpi)
+		}
+	case "size":
+		width, height, err := parseLabelSize(v.Value)
+		if err != nil {
+			return err
+		}
+		settings.PrintWidth, settings.PrintHeight = width, height
+	case "rotation":
+		rotation, err := strconv.Atoi(v.Value)
+		if err != nil || (rotation != 0 && rotation != 90 && rotation != 180 && rotation != 270) {
+			return fmt.Errorf("invalid rotation %q, use 0, 90, 180 or 270", v.Value)
+		}
+		settings.PrintRotation = float64(rotation)
+	case "mode":
+		switch strings.ToLower(v.Value) {
+		case "emulate":
+			PrintMode = 0
+		case "forward":
+			PrintMode = 1
+		case "relay":
+			PrintMode = 2
+		default:
+			return fmt.Errorf("invalid mode %q, use emulate, forward or relay", v.Value)
+		}
+	case "printer":
+		printer, err := findPrinter(a.db, v.Value)
+		if err != nil {
+			return err
+		}
+		SelectedPrinter = *printer
+	case "relayGroup":
+		ref := v.Value
+		if groupID, err := strconv.Atoi(ref); err == nil {
+			if id, ok := groupIDs[groupID]; ok && v.Source == ConfigSourceFile {
+				ref = strconv.Itoa(id)
+			}
+		}
+		group, err := findRelayGroup(a.db, ref)
+		if err != nil {
+			return err
+		}
+		LabelRelayGroup = *group
+	case "savePath":
+		settings.PrintPath = v.Value
+	}
+	return nil
+}
+
+// updateSettings changes the settings saved from the UI, stores them and rebuilds the running
+// settings with the config file and environment overrides on top. Only the saved copy is
+// stored, so removing an override brings back the value chosen in the UI.
+func (a *App) updateSettings(change func(s *Settings)) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	saved := *a.saved
+	change(&saved)
+	if err := saved.SaveToDB(a.db); err != nil {
+		return err
+	}
+	a.saved = &saved
+	a.Settings = a.withOverrides(&saved)
+	return nil
+}
+
+// reloadSettings reads the saved settings again after the database was changed directly
+func (a *App) reloadSettings() error {
+	saved, err := LoadSettingsFromDB(a.db)
+	if err != nil {
+		return err
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+	a.saved = saved
+	a.Settings = a.withOverrides(saved)
+	return nil
+}
+
+// withOverrides returns a copy of saved with the config file and environment settings
+// applied. Call it with configMu held.
+func (a *App) withOverrides(saved *Settings) *Settings {
+	settings := *saved
+	for _, v := range a.settingOverrides {
+		a.applyConfigValue(&settings, v.Key, v, nil)
+	}
+	return &settings
+}
+
+// printChoice is a print mode with its forward printer and relay group
+type printChoice struct {
+	mode    int
+	printer Printer
+	group   RelayGroup
+}
+
+// printSelection returns the print mode with its forward printer and relay group
+func printSelection() (int, Printer, RelayGroup) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return PrintMode, SelectedPrinter, LabelRelayGroup
+}
+
+// startConfig applies the config file and environment and, when a file is given, reloads it
+// whenever it changes
+func (a *App) startConfig(path string) {
+	a.configPath = path
+	a.setConfigReport(a.applyConfig(path))
+	if path == "" || a.configWatcher != nil {
+		return
+	}
+
+	w := &ConfigWatcher{quit: make(chan any), done: make(chan any)}
+	a.configWatcher = w
+	go func() {
+		defer close(w.done)
+		lastMod, lastSize := configFileStamp(path)
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.quit:
+				return
+			case <-ticker.C:
+			}
+			mod, size := configFileStamp(path)
+			if mod.Equal(lastMod) && size == lastSize {
+				continue
+			}
+			lastMod, lastSize = mod, size
+			report := a.applyConfig(path)
+			a.setConfigReport(report)
+			if report.Error != "" {
+				fmt.Println("Error reloading config:", report.Error)
+			} else {
+				fmt.Println("Reloaded config from", path)
+			}
+			a.ui.Emit("ConfigReloaded", report)
+		}
+	}()
+}
+
+func configFileStamp(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
+
+// stopConfig stops watching the config file
+func (a *App) stopConfig() {
+	if a.configWatcher == nil {
+		return
+	}
+	close(a.configWatcher.quit)
+	<-a.configWatcher.done
+	a.configWatcher = nil
+}
+
+func (a *App) setConfigReport(report *ConfigReport) {
+	configMu.Lock()
+	a.configReport = report
+	configMu.Unlock()
+}
+
+// GetConfigReport returns each configurable value with the source that won
+func (a *App) GetConfigReport() *ConfigReport {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return a.configReport
+}
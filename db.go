@@ -3,6 +3,7 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"os"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -18,8 +19,10 @@ const maxOpenDbConn = 10
 const maxIdleDbConn = 5
 const maxDbLifeTime = 5 * time.Minute
 
+// ConnectSQLLite3 opens the database with foreign keys enforced on every pooled connection,
+// so deleting a printer also removes it from relay groups
 func ConnectSQLLite3(dsn string) (*DB, error) {
-	d, err := sql.Open("sqlite", dsn)
+	d, err := sql.Open("sqlite", dsn+"?_pragma=foreign_keys(1)")
 	if err != nil {
 		return nil, err
 	}
@@ -40,10 +43,11 @@ func ConnectSQLLite3(dsn string) (*DB, error) {
 func TestDB(d *sql.DB) error {
 	err := d.Ping()
 	if err != nil {
-		fmt.Println("Error!", err)
+		fmt.Fprintln(os.Stderr, "Error!", err)
 		return err
 	}
-	fmt.Println("*** Pinged database successfully! ***")
+	// stderr keeps the CLI's stdout clean for exported bundles and JSON output
+	fmt.Fprintln(os.Stderr, "*** Pinged database successfully! ***")
 
 	return nil
 }
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Limits for network discovery
+const (
+	discoveryMaxHosts    = 4096
+	discoveryParallelism = 64
+	discoveryDialTimeout = 500 * time.Millisecond
+	discoveryProbeTime   = 2 * time.Second
+)
+
+// DiscoveredPrinter is a printer found on the network, pre-filled as a Printer record
+type DiscoveredPrinter struct {
+	Printer      Printer `json:"printer"`
+	Source       string  `json:"source"` // "mdns" or "scan"
+	Model        string  `json:"model"`
+	AlreadySaved bool    `json:"alreadySaved"`
+}
+
+// DiscoverPrinters browses mDNS and, if cidr is not empty, scans that range for ports 9100/631.
+// Every hit is probed (~HI for Zebra, Get-Printer-Attributes for IPP) to fill in the model.
+func (a *App) DiscoverPrinters(cidr string, timeoutSeconds int) ([]DiscoveredPrinter, error) {
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 3
+	}
+	timeout := time.Duration(timeoutSeconds) * time.Second
+
+	found := map[string]*DiscoveredPrinter{}
+	var mu sync.Mutex
+	add := func(d DiscoveredPrinter) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := net.JoinHostPort(d.Printer.IPAddress, fmt.Sprintf("%d", d.Printer.PrinterPort))
+		if existing, ok := found[key]; ok {
+			if existing.Model == "" {
+				existing.Model = d.Model
+			}
+			return
+		}
+		found[key] = &d
+	}
+
+	var wg sync.WaitGroup
+	var scanErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, d := range browseMDNSPrinters(timeout) {
+			add(d)
+		}
+	}()
+	if cidr != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := scanSubnetForPrinters(cidr)
+			if err != nil {
+				scanErr = err
+				return
+			}
+			for _, d := range results {
+				add(d)
+			}
+		}()
+	}
+	wg.Wait()
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	saved, _ := GetPrinters(a.db)
+	var results []DiscoveredPrinter
+	for _, d := range found {
+		for _, p := range saved {
+			if p.IPAddress == d.Printer.IPAddress && p.PrinterPort == d.Printer.PrinterPort {
+				d.AlreadySaved = true
+				break
+			}
+		}
+		results = append(results, *d)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Printer.IPAddress != results[j].Printer.IPAddress {
+			return results[i].Printer.IPAddress < results[j].Printer.IPAddress
+		}
+		return results[i].Printer.PrinterPort < results[j].Printer.PrinterPort
+	})
+	return results, nil
+}
+
+// browseMDNSPrinters sends DNS-SD PTR queries for printer services and collects the replies
+func browseMDNSPrinters(timeout time.Duration) []DiscoveredPrinter {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: 0})
+	if err != nil {
+		fmt.Println("Error opening mDNS browse socket:", err)
+		return nil
+	}
+	defer conn.Close()
+
+	groupAddr, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return nil
+	}
+	query := dnsmessage.Message{
+		Questions: []dnsmessage.Question{
+			{Name: mdnsName(mdnsIPPService), Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET},
+			{Name: mdnsName(mdnsPDLService), Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET},
+		},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return nil
+	}
+	if _, err := conn.WriteToUDP(packet, groupAddr); err != nil {
+		fmt.Println("Error sending mDNS query:", err)
+		return nil
+	}
+
+	// Records may arrive spread over several packets, so gather everything first
+	ptrs := map[string]string{} // instance -> service type
+	srvs := map[string]dnsmessage.SRVResource{}
+	txts := map[string][]string{}
+	addrs := map[string]net.IP{}
+	sources := map[string]net.IP{}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, 9000)
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+		var msg dnsmessage.Message
+		if err := msg.Unpack(buf[:n]); err != nil || !msg.Header.Response {
+			continue
+		}
+		for _, rr := range append(msg.Answers, msg.Additionals...) {
+			name := strings.ToLower(rr.Header.Name.String())
+			switch body := rr.Body.(type) {
+			case *dnsmessage.PTRResource:
+				if name == mdnsIPPService || name == mdnsPDLService {
+					instance := strings.ToLower(body.PTR.String())
+					ptrs[instance] = name
+					sources[instance] = src.IP
+				}
+			case *dnsmessage.SRVResource:
+				srvs[name] = *body
+			case *dnsmessage.TXTResource:
+				txts[name] = body.TXT
+			case *dnsmessage.AResource:
+				addrs[name] = net.IP(body.A[:])
+			}
+		}
+	}
+
+	var results []DiscoveredPrinter
+	for instance, serviceType := range ptrs {
+		srv, ok := srvs[instance]
+		if !ok {
+			continue
+		}
+		ip := addrs[strings.ToLower(srv.Target.String())]
+		if ip == nil {
+			ip = sources[instance]
+		}
+		txt := parseTXTRecords(txts[instance])
+		d := DiscoveredPrinter{
+			Source: "mdns",
+			Model:  txt["ty"],
+			Printer: Printer{
+				PrinterName: strings.TrimSuffix(instance, "."+serviceType),
+				IPAddress:   ip.String(),
+				PrinterPort: int(srv.Port),
+				PrinterType: "Zebra",
+			},
+		}
+		if serviceType == mdnsIPPService {
+			d.Printer.PrinterType = "IPP"
+			d.Printer.IPPEndpoint = "/" + strings.TrimPrefix(txt["rp"], "/")
+			if txt["rp"] == "" {
+				d.Printer.IPPEndpoint = "/ipp/print"
+			}
+		}
+		results = append(results, d)
+	}
+	return results
+}
+
+// parseTXTRecords splits DNS-SD key=value TXT strings into a map
+func parseTXTRecords(records []string) map[string]string {
+	values := map[string]string{}
+	for _, record := range records {
+		key, value, _ := strings.Cut(record, "=")
+		values[strings.ToLower(key)] = value
+	}
+	return values
+}
+
+// scanSubnetForPrinters probes every host in cidr on the raw (9100) and IPP (631) ports
+func scanSubnetForPrinters(cidr string) ([]DiscoveredPrinter, error) {
+	hosts, err := expandCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var results []DiscoveredPrinter
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, discoveryParallelism)
+	for _, host := range hosts {
+		for _, port := range []int{9100, 631} {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(host string, port int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				d, ok := probePrinter(host, port)
+				if !ok {
+					return
+				}
+				mu.Lock()
+				results = append(results, d)
+				mu.Unlock()
+			}(host, port)
+		}
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// expandCIDR lists the usable IPv4 host addresses in a CIDR range
+func expandCIDR(cidr string) ([]string, error) {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR range %q: %w", cidr, err)
+	}
+	if ip.To4() == nil {
+		return nil, fmt.Errorf("only IPv4 ranges can be scanned: %s", cidr)
+	}
+	ones, bits := ipNet.Mask.Size()
+	size := 1 << uint(bits-ones)
+	if size > discoveryMaxHosts {
+		return nil, fmt.Errorf("range %s has %d addresses, the maximum is %d", cidr, size, discoveryMaxHosts)
+	}
+
+	start := binary.BigEndian.Uint32(ipNet.IP.To4())
+	var hosts []string
+	for i := 0; i < size; i++ {
+		// Skip the network and broadcast addresses for anything larger than a /31
+		if size > 2 && (i == 0 || i == size-1) {
+			continue
+		}
+		addr := make(net.IP, 4)
+		binary.BigEndian.PutUint32(addr, start+uint32(i))
+		hosts = append(hosts, addr.String())
+	}
+	return hosts, nil
+}
+
+// probePrinter checks whether host:port answers like a printer and identifies it
+func probePrinter(host string, port int) (DiscoveredPrinter, bool) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, fmt.Sprintf("%d", port)), discoveryDialTimeout)
+	if err != nil {
+		return DiscoveredPrinter{}, false
+	}
+
+	d := DiscoveredPrinter{
+		Source: "scan",
+		Printer: Printer{
+			PrinterName: host,
+			IPAddress:   host,
+			PrinterPort: port,
+		},
+	}
+	// An open port is not enough: CUPS servers and other web services listen on 631 and
+	// plenty of devices accept connections on 9100, so the printer has to answer
+	if port == 631 {
+		conn.Close()
+		d.Printer.PrinterType = "IPP"
+		for _, endpoint := range []string{"/ipp/print", "/ipp"} {
+			attrs, err := getIPPPrinterAttributes(host, port, endpoint)
+			if err != nil {
+				continue
+			}
+			d.Printer.IPPEndpoint = endpoint
+			d.Model = attrs["printer-make-and-model"]
+			if attrs["printer-name"] != "" {
+				d.Printer.PrinterName = attrs["printer-name"]
+			}
+			return d, true
+		}
+		return DiscoveredPrinter{}, false
+	}
+
+	defer conn.Close()
+	d.Printer.PrinterType = "Zebra"
+	d.Model = probeZebraHostIdentification(conn)
+	if d.Model == "" {
+		return DiscoveredPrinter{}, false
+	}
+	d.Printer.PrinterName = fmt.Sprintf("%s (%s)", d.Model, host)
+	return d, true
+}
+
+// probeZebraHostIdentification sends ~HI and returns the model from the reply,
+// e.g. "\x02ZD420-203dpi,V84.20.11Z,8,8176KB\x03" yields "ZD420-203dpi". It returns ""
+// when there is no reply or it is not a host identification.
+func probeZebraHostIdentification(conn net.Conn) string {
+	conn.SetDeadline(time.Now().Add(discoveryProbeTime))
+	if _, err := conn.Write([]byte("~HI\r\n")); err != nil {
+		return ""
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\x03')
+	if err != nil && reply == "" {
+		return ""
+	}
+	// The reply is framed by STX/ETX and has at least the model and firmware version
+	start := strings.IndexByte(reply, '\x02')
+	if start < 0 {
+		return ""
+	}
+	fields := strings.Split(strings.Trim(reply[start:], "\x02\x03\r\n "), ",")
+	if len(fields) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(fields[0])
+}
+
+// getIPPPrinterAttributes sends an IPP Get-Printer-Attributes request and returns the
+// first value of each string-valued attribute in the response
+func getIPPPrinterAttributes(host string, port int, endpoint string) (map[string]string, error) {
+	url := fmt.Sprintf("http://%s:%d%s", host, port, endpoint)
+	printerURI := fmt.Sprintf("ipp://%s:%d%s", host, port, endpoint)
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, int8(2))
+	binary.Write(buf, binary.BigEndian, int8(0))
+	binary.Write(buf, binary.BigEndian, int16(ippOperationGetPrinterAttributes))
+	binary.Write(buf, binary.BigEndian, int32(1))
+
+	binary.Write(buf, binary.BigEndian, int8(ippTagOperation))
+	writeIPPAttribute(buf, ippTagCharset, "attributes-charset", []byte("utf-8"))
+	writeIPPAttribute(buf, ippTagLanguage, "attributes-natural-language", []byte("en-us"))
+	writeIPPAttribute(buf, ippTagUri, "printer-uri", []byte(printerURI))
+	writeIPPAttribute(buf, ippTagKeyword, "requested-attributes", []byte("printer-make-and-model"))
+	// Additional values of a multi-valued attribute use an empty name
+	writeIPPAttribute(buf, ippTagKeyword, "", []byte("printer-name"))
+	writeIPPAttribute(buf, ippTagKeyword, "", []byte("printer-state"))
+	binary.Write(buf, binary.BigEndian, int8(ippTagEnd))
+
+	client := &http.Client{Timeout: discoveryProbeTime}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", ippContentTypeIPP)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < 8 {
+		return nil, fmt.Errorf("response too short: %d bytes", len(body))
+	}
+	statusCode := int16(body[2])<<8 | int16(body[3])
+	if statusCode > 0x00FF {
+		return nil, fmt.Errorf("IPP error: status code 0x%04x", statusCode)
+	}
+	return parseIPPAttributes(body[8:]), nil
+}
+
+// parseIPPAttributes walks the attribute groups of an IPP response
+func parseIPPAttributes(data []byte) map[string]string {
+	attrs := map[string]string{}
+	lastName := ""
+	for len(data) > 0 {
+		tag := data[0]
+		data = data[1:]
+		if tag == ippTagEnd {
+			break
+		}
+		if tag < 0x10 {
+			// Group delimiter
+			continue
+		}
+		if len(data) < 2 {
+			break
+		}
+		nameLen := int(binary.BigEndian.Uint16(data))
+		data = data[2:]
+		if len(data) < nameLen+2 {
+			break
+		}
+		name := string(data[:nameLen])
+		data = data[nameLen:]
+		valueLen := int(binary.BigEndian.Uint16(data))
+		data = data[2:]
+		if len(data) < valueLen {
+			break
+		}
+		value := data[:valueLen]
+		data = data[valueLen:]
+
+		if name == "" {
+			name = lastName
+		}
+		lastName = name
+		if _, seen := attrs[name]; seen {
+			continue
+		}
+		switch {
+		case tag == ippTagInteger || tag == 0x23: // integer, enum
+			if len(value) == 4 {
+				attrs[name] = fmt.Sprintf("%d", int32(binary.BigEndian.Uint32(value)))
+			}
+		case tag >= 0x40:
+			attrs[name] = string(value)
+		}
+	}
+	return attrs
+}
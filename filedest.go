@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for File printers
+const (
+	defaultFileTemplate = "{printer}-{date}-{time}-{label}"
+	defaultFileFormats  = "zpl,png,pdf"
+)
+
+// fileNameCleaner strips characters that are not safe in file names on any platform
+var fileNameCleaner = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+
+// parseFileFormats splits a comma separated format list such as "zpl,pdf"
+func parseFileFormats(formats string) ([]string, error) {
+	if strings.TrimSpace(formats) == "" {
+		formats = defaultFileFormats
+	}
+	var list []string
+	for _, f := range strings.Split(formats, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		switch f {
+		case "zpl", "png", "pdf":
+			list = append(list, f)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown file format %q, use zpl, png or pdf", f)
+		}
+	}
+	return list, nil
+}
+
+// validateFileTemplate makes sure a template names a file inside the output directory
+func validateFileTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if strings.ContainsAny(template, `/\`) || strings.Contains(template, "..") {
+		return fmt.Errorf("file template %q must not contain path separators or '..'", template)
+	}
+	return nil
+}
+
+// expandFileTemplate fills in {printer}, {date}, {time}, {timestamp} and {label}.
+// ZPL is written once per job, so {label} is "all" for .zpl files.
+func expandFileTemplate(template string, printerName string, label string, now time.Time) string {
+	if template == "" {
+		template = defaultFileTemplate
+	}
+	name := strings.NewReplacer(
+		"{printer}", printerName,
+		"{date}", now.Format("20060102"),
+		"{time}", now.Format("150405"),
+		"{timestamp}", strconv.FormatInt(now.UnixMilli(), 10),
+		"{label}", label,
+	).Replace(template)
+	return fileNameCleaner.Replace(name)
+}
+
+// writeFileAtomically writes to a temporary name and links it into place, so programs
+// watching the directory never see a partial file. The link fails if the name exists, so
+// two jobs written at once never overwrite each other; the later one gets a -2, -3... suffix.
+func writeFileAtomically(dir string, name string, ext string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name+"."+ext)
+	for i := 2; ; i++ {
+		err := os.Link(tmp.Name(), path)
+		if err == nil {
+			return path, nil
+		}
+		if !os.IsExist(err) {
+			// Some network and FAT file systems have no hard links, so claim the name
+			// with an exclusive create and replace the empty placeholder
+			err = claimFileName(tmp.Name(), path)
+			if err == nil {
+				return path, nil
+			}
+			if !os.IsExist(err) {
+				return "", err
+			}
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.%s", name, i, ext))
+	}
+}
+
+// claimFileName creates path only if it does not exist yet and moves tmp over it
+func claimFileName(tmp string, path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// WriteToDirectory writes zpl to dir with the default File printer name template and formats
+func (a *App) WriteToDirectory(dir string, zpl string) error {
+	return a.writeToDirectory(context.Background(), Printer{PrinterName: "file", PrinterType: "File", OutputDir: dir}, zpl)
+}
+
+// writeToDirectory writes a job to a File printer's directory in each of its formats,
+// stopping before the next file once ctx is done
+func (a *App) writeToDirectory(ctx context.Context, p Printer, zpl string) error {
+	if p.OutputDir == "" {
+		return fmt.Errorf("no output directory configured for %s", p.PrinterName)
+	}
+	if err := validateFileTemplate(p.FileTemplate); err != nil {
+		return err
+	}
+	formats, err := parseFileFormats(p.FileFormats)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(p.OutputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %w", err)
+	}
+
+	now := time.Now()
+	var images [][]byte
+	rendered := false
+	for _, format := range formats {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if format == "zpl" {
+			name := expandFileTemplate(p.FileTemplate, p.PrinterName, "all", now)
+			if _, err := writeFileAtomically(p.OutputDir, name, "zpl", []byte(zpl)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !rendered {
+			images, err = a.renderLabels(ctx, zpl)
+			if err != nil {
+				return err
+			}
+			rendered = true
+		}
+		width, height := a.Settings.labelPageSize()
+		for i, pngBytes := range images {
+			data := pngBytes
+			if format == "pdf" {
+				data, err = convertPNGToPDF(pngBytes, width, height)
+				if err != nil {
+					return err
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			name := expandFileTemplate(p.FileTemplate, p.PrinterName, strconv.Itoa(i+1), now)
+			if _, err := writeFileAtomically(p.OutputDir, name, format, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
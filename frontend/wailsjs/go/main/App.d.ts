@@ -3,24 +3,78 @@
 import {main} from '../models';
 import {http} from '../models';
 
+export function AddFTPRoute(arg1:main.FTPRoute):Promise<number>;
+
+export function AddHotFolder(arg1:main.HotFolderRoute):Promise<number>;
+
 export function AddPrinter(arg1:main.Printer):Promise<void>;
 
 export function AddRelayGroup(arg1:Array<number>):Promise<void>;
 
+export function AddRoutingRule(arg1:main.RoutingRule):Promise<number>;
+
+export function ApplyRetention():Promise<main.RetentionReport>;
+
 export function CallLabelary(arg1:string,arg2:number,arg3:number,arg4:number):Promise<http.Response>;
 
 export function ClearPrintDirectory():Promise<void>;
 
+export function CompareJobWithGolden(arg1:number,arg2:number,arg3:string):Promise<main.CompareResult>;
+
+export function CreateRelayGroup(arg1:main.RelayGroup):Promise<number>;
+
+export function DeleteFTPRoute(arg1:number):Promise<void>;
+
+export function DeleteGolden(arg1:string):Promise<void>;
+
+export function DeleteHotFolder(arg1:number):Promise<void>;
+
 export function DeletePrinter(arg1:number):Promise<void>;
 
 export function DeleteRelayGroup(arg1:number):Promise<void>;
 
+export function DeleteRoutingRule(arg1:number):Promise<void>;
+
+export function DiffJobs(arg1:number,arg2:number):Promise<main.ZPLDiff>;
+
+export function DiscoverPrinters(arg1:string,arg2:number):Promise<Array<main.DiscoveredPrinter>>;
+
+export function ExportConfig():Promise<string>;
+
+export function ExportConfigAs(arg1:string):Promise<string>;
+
+export function GetAdvertiseMDNS():Promise<boolean>;
+
 export function GetAutoStart():Promise<boolean>;
 
 export function GetAutoStartServer():Promise<boolean>;
 
+export function GetConfigReport():Promise<main.ConfigReport>;
+
+export function GetFTPEnabled():Promise<boolean>;
+
+export function GetFTPPort():Promise<number>;
+
+export function GetFTPRoutes():Promise<Array<main.FTPRoute>>;
+
+export function GetFTPUser():Promise<string>;
+
+export function GetGoldenCompareOptions():Promise<main.CompareOptions>;
+
+export function GetGoldenResults():Promise<Array<main.CompareResult>>;
+
 export function GetHeight():Promise<number>;
 
+export function GetHotFolderPath():Promise<string>;
+
+export function GetHotFolders():Promise<Array<main.HotFolderRoute>>;
+
+export function GetJob(arg1:number):Promise<main.Job>;
+
+export function GetJobs(arg1:number,arg2:number):Promise<main.JobPage>;
+
+export function GetLocalPrinters():Promise<Array<main.LocalPrinter>>;
+
 export function GetPrintDirectory():Promise<string>;
 
 export function GetPrinterDPI():Promise<main.PrinterDPI>;
@@ -31,21 +85,67 @@ export function GetPrinterRotation():Promise<number>;
 
 export function GetPrinterRunStatus():Promise<boolean>;
 
+export function GetPrinterStats():Promise<main.PrinterStats>;
+
 export function GetPrinters():Promise<Array<main.Printer>>;
 
 export function GetRelayGroups():Promise<Array<main.RelayGroup>>;
 
+export function GetRelayParallel():Promise<number>;
+
+export function GetRelayTimeout():Promise<number>;
+
+export function GetRetentionPolicy():Promise<main.RetentionPolicy>;
+
+export function GetRoutingRules():Promise<Array<main.RoutingRule>>;
+
+export function GetSNMPBind():Promise<string>;
+
+export function GetSNMPCommunity():Promise<string>;
+
+export function GetSNMPEnabled():Promise<boolean>;
+
+export function GetSNMPPort():Promise<number>;
+
 export function GetVersion():Promise<string>;
 
 export function GetWidth():Promise<number>;
 
+export function ImportConfig(arg1:string,arg2:string):Promise<main.ImportReport>;
+
+export function ListGoldens():Promise<Array<string>>;
+
+export function NewFTPServer():Promise<main.FTPServer>;
+
+export function NewHotFolder(arg1:string,arg2:main.HotFolderRoute):Promise<main.HotFolder>;
+
+export function NewMDNSResponder(arg1:number):Promise<main.MDNSResponder>;
+
+export function NewSNMPAgent():Promise<main.SNMPAgent>;
+
 export function NewTCPServer():Promise<main.TCPServer>;
 
+export function PreviewRetention():Promise<main.RetentionReport>;
+
+export function PrintToLocalQueue(arg1:string,arg2:string):Promise<void>;
+
 export function ProcessAndSendToPrinter(arg1:string,arg2:string,arg3:number,arg4:string):Promise<void>;
 
 export function ProcessAndSendToPrinterWithIPP(arg1:string,arg2:string,arg3:number,arg4:string,arg5:string,arg6:boolean):Promise<void>;
 
-export function ProcessRelayGroup(arg1:string):Promise<void>;
+export function ProcessRelayGroup(arg1:string):Promise<Array<main.JobOutcome>>;
+
+export function ReorderRoutingRules(arg1:Array<number>):Promise<void>;
+
+export function ReprintJob(arg1:number):Promise<Array<any>>;
+
+export function ResendJobToPrinter(arg1:number,arg2:number):Promise<main.ResendResult>;
+
+export function ResendJobToRelayGroup(arg1:number,arg2:number):Promise<main.ResendResult>;
+
+export function SaveGoldenFromJob(arg1:number,arg2:number,arg3:string):Promise<void>;
+
+export function SearchJobs(arg1:string,arg2:number):Promise<Array<main.JobSearchResult>>;
 
 export function SelectPrinter(arg1:main.Printer):Promise<void>;
 
@@ -53,10 +153,22 @@ export function SelectRelayGroup(arg1:main.RelayGroup):Promise<void>;
 
 export function SendToLabelary(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function SendToPrinter(arg1:main.Printer,arg2:string):Promise<void>;
+
+export function SetAdvertiseMDNS(arg1:boolean):Promise<void>;
+
 export function SetAutoStart(arg1:boolean):Promise<void>;
 
 export function SetAutoStartServer(arg1:boolean):Promise<void>;
 
+export function SetFTPCredentials(arg1:string,arg2:string):Promise<void>;
+
+export function SetFTPEnabled(arg1:boolean):Promise<void>;
+
+export function SetGoldenCompareOptions(arg1:main.CompareOptions):Promise<void>;
+
+export function SetHotFolderPath(arg1:string):Promise<void>;
+
 export function SetPrintDirectory():Promise<string>;
 
 export function SetPrinterEmulatorMode():Promise<void>;
@@ -67,18 +179,46 @@ export function SetPrinterRotation(arg1:number):Promise<void>;
 
 export function SetPrinterZPLToPrinterMode():Promise<void>;
 
+export function SetRelayGroupStrategy(arg1:number,arg2:string):Promise<void>;
+
+export function SetRelayLimits(arg1:number,arg2:number):Promise<void>;
+
+export function SetRetentionPolicy(arg1:main.RetentionPolicy):Promise<void>;
+
+export function SetSNMPEnabled(arg1:boolean):Promise<void>;
+
 export function StartPrinterServer():Promise<void>;
 
 export function StopPrintServer():Promise<void>;
 
+export function TestRoutingRule(arg1:string,arg2:number,arg3:string):Promise<main.RouteDecision>;
+
+export function UpdateFTPPort(arg1:number):Promise<void>;
+
+export function UpdateFTPRoute(arg1:main.FTPRoute):Promise<void>;
+
 export function UpdateHeight(arg1:number):Promise<void>;
 
+export function UpdateHotFolder(arg1:main.HotFolderRoute):Promise<void>;
+
 export function UpdatePrinter(arg1:main.Printer):Promise<void>;
 
 export function UpdatePrinterDPI(arg1:main.PrinterDPI):Promise<void>;
 
 export function UpdatePrinterPort(arg1:number):Promise<void>;
 
+export function UpdateRelayGroup(arg1:main.RelayGroup):Promise<void>;
+
+export function UpdateRoutingRule(arg1:main.RoutingRule):Promise<void>;
+
+export function UpdateSNMPBind(arg1:string):Promise<void>;
+
+export function UpdateSNMPCommunity(arg1:string):Promise<void>;
+
+export function UpdateSNMPPort(arg1:number):Promise<void>;
+
 export function UpdateSave(arg1:boolean):Promise<void>;
 
 export function UpdateWidth(arg1:number):Promise<void>;
+
+export function WriteToDirectory(arg1:string,arg2:string):Promise<void>;
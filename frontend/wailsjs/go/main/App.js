@@ -2,6 +2,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddFTPRoute(arg1) {
+  return window['go']['main']['App']['AddFTPRoute'](arg1);
+}
+
+export function AddHotFolder(arg1) {
+  return window['go']['main']['App']['AddHotFolder'](arg1);
+}
+
 export function AddPrinter(arg1) {
   return window['go']['main']['App']['AddPrinter'](arg1);
 }
@@ -10,6 +18,14 @@ export function AddRelayGroup(arg1) {
   return window['go']['main']['App']['AddRelayGroup'](arg1);
 }
 
+export function AddRoutingRule(arg1) {
+  return window['go']['main']['App']['AddRoutingRule'](arg1);
+}
+
+export function ApplyRetention() {
+  return window['go']['main']['App']['ApplyRetention']();
+}
+
 export function CallLabelary(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['CallLabelary'](arg1, arg2, arg3, arg4);
 }
@@ -18,6 +34,26 @@ export function ClearPrintDirectory() {
   return window['go']['main']['App']['ClearPrintDirectory']();
 }
 
+export function CompareJobWithGolden(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CompareJobWithGolden'](arg1, arg2, arg3);
+}
+
+export function CreateRelayGroup(arg1) {
+  return window['go']['main']['App']['CreateRelayGroup'](arg1);
+}
+
+export function DeleteFTPRoute(arg1) {
+  return window['go']['main']['App']['DeleteFTPRoute'](arg1);
+}
+
+export function DeleteGolden(arg1) {
+  return window['go']['main']['App']['DeleteGolden'](arg1);
+}
+
+export function DeleteHotFolder(arg1) {
+  return window['go']['main']['App']['DeleteHotFolder'](arg1);
+}
+
 export function DeletePrinter(arg1) {
   return window['go']['main']['App']['DeletePrinter'](arg1);
 }
@@ -26,6 +62,30 @@ export function DeleteRelayGroup(arg1) {
   return window['go']['main']['App']['DeleteRelayGroup'](arg1);
 }
 
+export function DeleteRoutingRule(arg1) {
+  return window['go']['main']['App']['DeleteRoutingRule'](arg1);
+}
+
+export function DiffJobs(arg1, arg2) {
+  return window['go']['main']['App']['DiffJobs'](arg1, arg2);
+}
+
+export function DiscoverPrinters(arg1, arg2) {
+  return window['go']['main']['App']['DiscoverPrinters'](arg1, arg2);
+}
+
+export function ExportConfig() {
+  return window['go']['main']['App']['ExportConfig']();
+}
+
+export function ExportConfigAs(arg1) {
+  return window['go']['main']['App']['ExportConfigAs'](arg1);
+}
+
+export function GetAdvertiseMDNS() {
+  return window['go']['main']['App']['GetAdvertiseMDNS']();
+}
+
 export function GetAutoStart() {
   return window['go']['main']['App']['GetAutoStart']();
 }
@@ -34,10 +94,58 @@ export function GetAutoStartServer() {
   return window['go']['main']['App']['GetAutoStartServer']();
 }
 
+export function GetConfigReport() {
+  return window['go']['main']['App']['GetConfigReport']();
+}
+
+export function GetFTPEnabled() {
+  return window['go']['main']['App']['GetFTPEnabled']();
+}
+
+export function GetFTPPort() {
+  return window['go']['main']['App']['GetFTPPort']();
+}
+
+export function GetFTPRoutes() {
+  return window['go']['main']['App']['GetFTPRoutes']();
+}
+
+export function GetFTPUser() {
+  return window['go']['main']['App']['GetFTPUser']();
+}
+
+export function GetGoldenCompareOptions() {
+  return window['go']['main']['App']['GetGoldenCompareOptions']();
+}
+
+export function GetGoldenResults() {
+  return window['go']['main']['App']['GetGoldenResults']();
+}
+
 export function GetHeight() {
   return window['go']['main']['App']['GetHeight']();
 }
 
+export function GetHotFolderPath() {
+  return window['go']['main']['App']['GetHotFolderPath']();
+}
+
+export function GetHotFolders() {
+  return window['go']['main']['App']['GetHotFolders']();
+}
+
+export function GetJob(arg1) {
+  return window['go']['main']['App']['GetJob'](arg1);
+}
+
+export function GetJobs(arg1, arg2) {
+  return window['go']['main']['App']['GetJobs'](arg1, arg2);
+}
+
+export function GetLocalPrinters() {
+  return window['go']['main']['App']['GetLocalPrinters']();
+}
+
 export function GetPrintDirectory() {
   return window['go']['main']['App']['GetPrintDirectory']();
 }
@@ -58,6 +166,10 @@ export function GetPrinterRunStatus() {
   return window['go']['main']['App']['GetPrinterRunStatus']();
 }
 
+export function GetPrinterStats() {
+  return window['go']['main']['App']['GetPrinterStats']();
+}
+
 export function GetPrinters() {
   return window['go']['main']['App']['GetPrinters']();
 }
@@ -66,6 +178,38 @@ export function GetRelayGroups() {
   return window['go']['main']['App']['GetRelayGroups']();
 }
 
+export function GetRelayParallel() {
+  return window['go']['main']['App']['GetRelayParallel']();
+}
+
+export function GetRelayTimeout() {
+  return window['go']['main']['App']['GetRelayTimeout']();
+}
+
+export function GetRetentionPolicy() {
+  return window['go']['main']['App']['GetRetentionPolicy']();
+}
+
+export function GetRoutingRules() {
+  return window['go']['main']['App']['GetRoutingRules']();
+}
+
+export function GetSNMPBind() {
+  return window['go']['main']['App']['GetSNMPBind']();
+}
+
+export function GetSNMPCommunity() {
+  return window['go']['main']['App']['GetSNMPCommunity']();
+}
+
+export function GetSNMPEnabled() {
+  return window['go']['main']['App']['GetSNMPEnabled']();
+}
+
+export function GetSNMPPort() {
+  return window['go']['main']['App']['GetSNMPPort']();
+}
+
 export function GetVersion() {
   return window['go']['main']['App']['GetVersion']();
 }
@@ -74,10 +218,42 @@ export function GetWidth() {
   return window['go']['main']['App']['GetWidth']();
 }
 
+export function ImportConfig(arg1, arg2) {
+  return window['go']['main']['App']['ImportConfig'](arg1, arg2);
+}
+
+export function ListGoldens() {
+  return window['go']['main']['App']['ListGoldens']();
+}
+
+export function NewFTPServer() {
+  return window['go']['main']['App']['NewFTPServer']();
+}
+
+export function NewHotFolder(arg1, arg2) {
+  return window['go']['main']['App']['NewHotFolder'](arg1, arg2);
+}
+
+export function NewMDNSResponder(arg1) {
+  return window['go']['main']['App']['NewMDNSResponder'](arg1);
+}
+
+export function NewSNMPAgent() {
+  return window['go']['main']['App']['NewSNMPAgent']();
+}
+
 export function NewTCPServer() {
   return window['go']['main']['App']['NewTCPServer']();
 }
 
+export function PreviewRetention() {
+  return window['go']['main']['App']['PreviewRetention']();
+}
+
+export function PrintToLocalQueue(arg1, arg2) {
+  return window['go']['main']['App']['PrintToLocalQueue'](arg1, arg2);
+}
+
 export function ProcessAndSendToPrinter(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['ProcessAndSendToPrinter'](arg1, arg2, arg3, arg4);
 }
@@ -90,6 +266,30 @@ export function ProcessRelayGroup(arg1) {
   return window['go']['main']['App']['ProcessRelayGroup'](arg1);
 }
 
+export function ReorderRoutingRules(arg1) {
+  return window['go']['main']['App']['ReorderRoutingRules'](arg1);
+}
+
+export function ReprintJob(arg1) {
+  return window['go']['main']['App']['ReprintJob'](arg1);
+}
+
+export function ResendJobToPrinter(arg1, arg2) {
+  return window['go']['main']['App']['ResendJobToPrinter'](arg1, arg2);
+}
+
+export function ResendJobToRelayGroup(arg1, arg2) {
+  return window['go']['main']['App']['ResendJobToRelayGroup'](arg1, arg2);
+}
+
+export function SaveGoldenFromJob(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveGoldenFromJob'](arg1, arg2, arg3);
+}
+
+export function SearchJobs(arg1, arg2) {
+  return window['go']['main']['App']['SearchJobs'](arg1, arg2);
+}
+
 export function SelectPrinter(arg1) {
   return window['go']['main']['App']['SelectPrinter'](arg1);
 }
@@ -102,6 +302,14 @@ export function SendToLabelary(arg1, arg2, arg3) {
   return window['go']['main']['App']['SendToLabelary'](arg1, arg2, arg3);
 }
 
+export function SendToPrinter(arg1, arg2) {
+  return window['go']['main']['App']['SendToPrinter'](arg1, arg2);
+}
+
+export function SetAdvertiseMDNS(arg1) {
+  return window['go']['main']['App']['SetAdvertiseMDNS'](arg1);
+}
+
 export function SetAutoStart(arg1) {
   return window['go']['main']['App']['SetAutoStart'](arg1);
 }
@@ -110,6 +318,22 @@ export function SetAutoStartServer(arg1) {
   return window['go']['main']['App']['SetAutoStartServer'](arg1);
 }
 
+export function SetFTPCredentials(arg1, arg2) {
+  return window['go']['main']['App']['SetFTPCredentials'](arg1, arg2);
+}
+
+export function SetFTPEnabled(arg1) {
+  return window['go']['main']['App']['SetFTPEnabled'](arg1);
+}
+
+export function SetGoldenCompareOptions(arg1) {
+  return window['go']['main']['App']['SetGoldenCompareOptions'](arg1);
+}
+
+export function SetHotFolderPath(arg1) {
+  return window['go']['main']['App']['SetHotFolderPath'](arg1);
+}
+
 export function SetPrintDirectory() {
   return window['go']['main']['App']['SetPrintDirectory']();
 }
@@ -130,6 +354,22 @@ export function SetPrinterZPLToPrinterMode() {
   return window['go']['main']['App']['SetPrinterZPLToPrinterMode']();
 }
 
+export function SetRelayGroupStrategy(arg1, arg2) {
+  return window['go']['main']['App']['SetRelayGroupStrategy'](arg1, arg2);
+}
+
+export function SetRelayLimits(arg1, arg2) {
+  return window['go']['main']['App']['SetRelayLimits'](arg1, arg2);
+}
+
+export function SetRetentionPolicy(arg1) {
+  return window['go']['main']['App']['SetRetentionPolicy'](arg1);
+}
+
+export function SetSNMPEnabled(arg1) {
+  return window['go']['main']['App']['SetSNMPEnabled'](arg1);
+}
+
 export function StartPrinterServer() {
   return window['go']['main']['App']['StartPrinterServer']();
 }
@@ -138,10 +378,26 @@ export function StopPrintServer() {
   return window['go']['main']['App']['StopPrintServer']();
 }
 
+export function TestRoutingRule(arg1, arg2, arg3) {
+  return window['go']['main']['App']['TestRoutingRule'](arg1, arg2, arg3);
+}
+
+export function UpdateFTPPort(arg1) {
+  return window['go']['main']['App']['UpdateFTPPort'](arg1);
+}
+
+export function UpdateFTPRoute(arg1) {
+  return window['go']['main']['App']['UpdateFTPRoute'](arg1);
+}
+
 export function UpdateHeight(arg1) {
   return window['go']['main']['App']['UpdateHeight'](arg1);
 }
 
+export function UpdateHotFolder(arg1) {
+  return window['go']['main']['App']['UpdateHotFolder'](arg1);
+}
+
 export function UpdatePrinter(arg1) {
   return window['go']['main']['App']['UpdatePrinter'](arg1);
 }
@@ -154,6 +410,26 @@ export function UpdatePrinterPort(arg1) {
   return window['go']['main']['App']['UpdatePrinterPort'](arg1);
 }
 
+export function UpdateRelayGroup(arg1) {
+  return window['go']['main']['App']['UpdateRelayGroup'](arg1);
+}
+
+export function UpdateRoutingRule(arg1) {
+  return window['go']['main']['App']['UpdateRoutingRule'](arg1);
+}
+
+export function UpdateSNMPBind(arg1) {
+  return window['go']['main']['App']['UpdateSNMPBind'](arg1);
+}
+
+export function UpdateSNMPCommunity(arg1) {
+  return window['go']['main']['App']['UpdateSNMPCommunity'](arg1);
+}
+
+export function UpdateSNMPPort(arg1) {
+  return window['go']['main']['App']['UpdateSNMPPort'](arg1);
+}
+
 export function UpdateSave(arg1) {
   return window['go']['main']['App']['UpdateSave'](arg1);
 }
@@ -161,3 +437,7 @@ export function UpdateSave(arg1) {
 export function UpdateWidth(arg1) {
   return window['go']['main']['App']['UpdateWidth'](arg1);
 }
+
+export function WriteToDirectory(arg1, arg2) {
+  return window['go']['main']['App']['WriteToDirectory'](arg1, arg2);
+}
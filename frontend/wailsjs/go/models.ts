@@ -129,6 +129,140 @@ export namespace http {
 
 export namespace main {
 	
+	export class CompareOptions {
+	    colorTolerance: number;
+	    maxDiffPixels: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompareOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.colorTolerance = source["colorTolerance"];
+	        this.maxDiffPixels = source["maxDiffPixels"];
+	    }
+	}
+	export class DiffRegion {
+	    x: number;
+	    y: number;
+	    width: number;
+	    height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffRegion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	    }
+	}
+	export class CompareResult {
+	    name: string;
+	    labelIndex: number;
+	    status: string;
+	    passed: boolean;
+	    diffPixels: number;
+	    diffRatio: number;
+	    regions: DiffRegion[];
+	    diffImage?: number[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompareResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.labelIndex = source["labelIndex"];
+	        this.status = source["status"];
+	        this.passed = source["passed"];
+	        this.diffPixels = source["diffPixels"];
+	        this.diffRatio = source["diffRatio"];
+	        this.regions = this.convertValues(source["regions"], DiffRegion);
+	        this.diffImage = source["diffImage"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConfigValue {
+	    key: string;
+	    value: string;
+	    source: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigValue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.source = source["source"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ConfigReport {
+	    path: string;
+	    loadedAt: string;
+	    values: ConfigValue[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.loadedAt = source["loadedAt"];
+	        this.values = this.convertValues(source["values"], ConfigValue);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
 	export class Printer {
 	    printerID: number;
 	    printerName: string;
@@ -137,6 +271,10 @@ export namespace main {
 	    printerType: string;
 	    ippEndpoint: string;
 	    useTLS: boolean;
+	    queueName: string;
+	    outputDir: string;
+	    fileTemplate: string;
+	    fileFormats: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Printer(source);
@@ -151,8 +289,337 @@ export namespace main {
 	        this.printerType = source["printerType"];
 	        this.ippEndpoint = source["ippEndpoint"];
 	        this.useTLS = source["useTLS"];
+	        this.queueName = source["queueName"];
+	        this.outputDir = source["outputDir"];
+	        this.fileTemplate = source["fileTemplate"];
+	        this.fileFormats = source["fileFormats"];
 	    }
 	}
+	export class DiscoveredPrinter {
+	    printer: Printer;
+	    source: string;
+	    model: string;
+	    alreadySaved: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiscoveredPrinter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.printer = this.convertValues(source["printer"], Printer);
+	        this.source = source["source"];
+	        this.model = source["model"];
+	        this.alreadySaved = source["alreadySaved"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FTPRoute {
+	    routeID: number;
+	    user: string;
+	    directory: string;
+	    action: string;
+	    targetID: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FTPRoute(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.routeID = source["routeID"];
+	        this.user = source["user"];
+	        this.directory = source["directory"];
+	        this.action = source["action"];
+	        this.targetID = source["targetID"];
+	    }
+	}
+	export class FTPServer {
+	
+	
+	    static createFrom(source: any = {}) {
+	        return new FTPServer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	
+	    }
+	}
+	export class HotFolder {
+	
+	
+	    static createFrom(source: any = {}) {
+	        return new HotFolder(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	
+	    }
+	}
+	export class HotFolderRoute {
+	    folderID: number;
+	    path: string;
+	    enabled: boolean;
+	    action: string;
+	    targetID: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HotFolderRoute(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.folderID = source["folderID"];
+	        this.path = source["path"];
+	        this.enabled = source["enabled"];
+	        this.action = source["action"];
+	        this.targetID = source["targetID"];
+	    }
+	}
+	export class ImportReport {
+	    mode: string;
+	    settingsApplied: boolean;
+	    printersAdded: number;
+	    printersUpdated: number;
+	    groupsAdded: number;
+	    groupsUpdated: number;
+	    groupsSkipped: number;
+	    printerIDs: Record<number, number>;
+	    groupIDs: Record<number, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.settingsApplied = source["settingsApplied"];
+	        this.printersAdded = source["printersAdded"];
+	        this.printersUpdated = source["printersUpdated"];
+	        this.groupsAdded = source["groupsAdded"];
+	        this.groupsUpdated = source["groupsUpdated"];
+	        this.groupsSkipped = source["groupsSkipped"];
+	        this.printerIDs = source["printerIDs"];
+	        this.groupIDs = source["groupIDs"];
+	    }
+	}
+	export class JobOutcome {
+	    printerID: number;
+	    printerName: string;
+	    success: boolean;
+	    error: string;
+	    // Go type: time
+	    sentAt: any;
+	    latencyMs: number;
+	    failedOver: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobOutcome(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.printerID = source["printerID"];
+	        this.printerName = source["printerName"];
+	        this.success = source["success"];
+	        this.error = source["error"];
+	        this.sentAt = this.convertValues(source["sentAt"], null);
+	        this.latencyMs = source["latencyMs"];
+	        this.failedOver = source["failedOver"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Job {
+	    jobID: number;
+	    // Go type: time
+	    receivedAt: any;
+	    // Go type: time
+	    completedAt: any;
+	    sourceAddress: string;
+	    listener: string;
+	    mode: string;
+	    byteCount: number;
+	    labelCount: number;
+	    status: string;
+	    error: string;
+	    rawData?: number[];
+	    images?: number[][];
+	    outcomes?: JobOutcome[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Job(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.jobID = source["jobID"];
+	        this.receivedAt = this.convertValues(source["receivedAt"], null);
+	        this.completedAt = this.convertValues(source["completedAt"], null);
+	        this.sourceAddress = source["sourceAddress"];
+	        this.listener = source["listener"];
+	        this.mode = source["mode"];
+	        this.byteCount = source["byteCount"];
+	        this.labelCount = source["labelCount"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.rawData = source["rawData"];
+	        this.images = source["images"];
+	        this.outcomes = this.convertValues(source["outcomes"], JobOutcome);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class JobPage {
+	    jobs: Job[];
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.jobs = this.convertValues(source["jobs"], Job);
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JobSearchResult {
+	    job: Job;
+	    snippet: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.job = this.convertValues(source["job"], Job);
+	        this.snippet = source["snippet"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LocalPrinter {
+	    name: string;
+	    state: string;
+	    isDefault: boolean;
+	    media: string[];
+	    defaultMedia: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LocalPrinter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.state = source["state"];
+	        this.isDefault = source["isDefault"];
+	        this.media = source["media"];
+	        this.defaultMedia = source["defaultMedia"];
+	    }
+	}
+	export class MDNSResponder {
+	
+	
+	    static createFrom(source: any = {}) {
+	        return new MDNSResponder(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	
+	    }
+	}
+	
 	export class PrinterDPI {
 	    value: number;
 	    desc: string;
@@ -167,8 +634,72 @@ export namespace main {
 	        this.desc = source["desc"];
 	    }
 	}
+	export class PrinterStats {
+	    // Go type: time
+	    startedAt: any;
+	    jobsReceived: number;
+	    bytesReceived: number;
+	    labelsPrinted: number;
+	    activeJobs: number;
+	    // Go type: time
+	    lastJobTime: any;
+	    lastError: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PrinterStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.jobsReceived = source["jobsReceived"];
+	        this.bytesReceived = source["bytesReceived"];
+	        this.labelsPrinted = source["labelsPrinted"];
+	        this.activeJobs = source["activeJobs"];
+	        this.lastJobTime = this.convertValues(source["lastJobTime"], null);
+	        this.lastError = source["lastError"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RelayGroupMember {
+	    printerID: number;
+	    printerName: string;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RelayGroupMember(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.printerID = source["printerID"];
+	        this.printerName = source["printerName"];
+	        this.enabled = source["enabled"];
+	    }
+	}
 	export class RelayGroup {
 	    groupID: number;
+	    name: string;
+	    description: string;
+	    strategy: string;
+	    members: RelayGroupMember[];
 	    printerIDs: number[];
 	
 	    static createFrom(source: any = {}) {
@@ -178,8 +709,208 @@ export namespace main {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.groupID = source["groupID"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.strategy = source["strategy"];
+	        this.members = this.convertValues(source["members"], RelayGroupMember);
 	        this.printerIDs = source["printerIDs"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ResendResult {
+	    jobID: number;
+	    outcomes: JobOutcome[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ResendResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.jobID = source["jobID"];
+	        this.outcomes = this.convertValues(source["outcomes"], JobOutcome);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RetentionPolicy {
+	    maxAgeDays: number;
+	    maxCount: number;
+	    maxTotalMB: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetentionPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxAgeDays = source["maxAgeDays"];
+	        this.maxCount = source["maxCount"];
+	        this.maxTotalMB = source["maxTotalMB"];
+	    }
+	}
+	export class RetentionReport {
+	    dryRun: boolean;
+	    files: string[];
+	    fileBytes: number;
+	    jobIDs: number[];
+	    jobBytes: number;
+	    ranAt: string;
+	    errorMessage: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetentionReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dryRun = source["dryRun"];
+	        this.files = source["files"];
+	        this.fileBytes = source["fileBytes"];
+	        this.jobIDs = source["jobIDs"];
+	        this.jobBytes = source["jobBytes"];
+	        this.ranAt = source["ranAt"];
+	        this.errorMessage = source["errorMessage"];
+	    }
+	}
+	export class RuleCheck {
+	    ruleID: number;
+	    name: string;
+	    matched: boolean;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleID = source["ruleID"];
+	        this.name = source["name"];
+	        this.matched = source["matched"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class RouteDecision {
+	    ruleID: number;
+	    ruleName: string;
+	    action: string;
+	    targetID: number;
+	    checks: RuleCheck[];
+	    summary: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RouteDecision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleID = source["ruleID"];
+	        this.ruleName = source["ruleName"];
+	        this.action = source["action"];
+	        this.targetID = source["targetID"];
+	        this.checks = this.convertValues(source["checks"], RuleCheck);
+	        this.summary = source["summary"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RoutingRule {
+	    ruleID: number;
+	    position: number;
+	    name: string;
+	    enabled: boolean;
+	    isDefault: boolean;
+	    sourceCIDR: string;
+	    listenerPort: number;
+	    zplPattern: string;
+	    comment: string;
+	    labelSize: string;
+	    action: string;
+	    targetID: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoutingRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleID = source["ruleID"];
+	        this.position = source["position"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.isDefault = source["isDefault"];
+	        this.sourceCIDR = source["sourceCIDR"];
+	        this.listenerPort = source["listenerPort"];
+	        this.zplPattern = source["zplPattern"];
+	        this.comment = source["comment"];
+	        this.labelSize = source["labelSize"];
+	        this.action = source["action"];
+	        this.targetID = source["targetID"];
+	    }
+	}
+	
+	export class SNMPAgent {
+	
+	
+	    static createFrom(source: any = {}) {
+	        return new SNMPAgent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	
+	    }
 	}
 	export class TCPServer {
 	
@@ -193,6 +924,60 @@ export namespace main {
 	
 	    }
 	}
+	export class ZPLChange {
+	    label: number;
+	    type: string;
+	    element: string;
+	    attribute: string;
+	    old: string;
+	    new: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ZPLChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.label = source["label"];
+	        this.type = source["type"];
+	        this.element = source["element"];
+	        this.attribute = source["attribute"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class ZPLDiff {
+	    identical: boolean;
+	    changes: ZPLChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ZPLDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.identical = source["identical"];
+	        this.changes = this.convertValues(source["changes"], ZPLChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -370,6 +1155,8 @@ export namespace tls {
 	    OCSPResponse: number[];
 	    TLSUnique: number[];
 	    ECHAccepted: boolean;
+	    HelloRetryRequest: boolean;
+	    LocalCertificate: number[][];
 	
 	    static createFrom(source: any = {}) {
 	        return new ConnectionState(source);
@@ -391,6 +1178,8 @@ export namespace tls {
 	        this.OCSPResponse = source["OCSPResponse"];
 	        this.TLSUnique = source["TLSUnique"];
 	        this.ECHAccepted = source["ECHAccepted"];
+	        this.HelloRetryRequest = source["HelloRetryRequest"];
+	        this.LocalCertificate = source["LocalCertificate"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -435,12 +1224,12 @@ export namespace url {
 	    User?: any;
 	    Host: string;
 	    Path: string;
-	    RawPath: string;
-	    OmitHost: boolean;
-	    ForceQuery: boolean;
-	    RawQuery: string;
 	    Fragment: string;
+	    RawQuery: string;
+	    RawPath: string;
 	    RawFragment: string;
+	    ForceQuery: boolean;
+	    OmitHost: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new URL(source);
@@ -453,12 +1242,12 @@ export namespace url {
 	        this.User = this.convertValues(source["User"], null);
 	        this.Host = source["Host"];
 	        this.Path = source["Path"];
-	        this.RawPath = source["RawPath"];
-	        this.OmitHost = source["OmitHost"];
-	        this.ForceQuery = source["ForceQuery"];
-	        this.RawQuery = source["RawQuery"];
 	        this.Fragment = source["Fragment"];
+	        this.RawQuery = source["RawQuery"];
+	        this.RawPath = source["RawPath"];
 	        this.RawFragment = source["RawFragment"];
+	        this.ForceQuery = source["ForceQuery"];
+	        this.OmitHost = source["OmitHost"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -536,6 +1325,7 @@ export namespace x509 {
 	    RawSubjectPublicKeyInfo: number[];
 	    RawSubject: number[];
 	    RawIssuer: number[];
+	    RawSignatureAlgorithm: number[];
 	    Signature: number[];
 	    SignatureAlgorithm: number;
 	    PublicKeyAlgorithm: number;
@@ -598,6 +1388,7 @@ export namespace x509 {
 	        this.RawSubjectPublicKeyInfo = source["RawSubjectPublicKeyInfo"];
 	        this.RawSubject = source["RawSubject"];
 	        this.RawIssuer = source["RawIssuer"];
+	        this.RawSignatureAlgorithm = source["RawSignatureAlgorithm"];
 	        this.Signature = source["Signature"];
 	        this.SignatureAlgorithm = source["SignatureAlgorithm"];
 	        this.PublicKeyAlgorithm = source["PublicKeyAlgorithm"];
@@ -0,0 +1,563 @@
+package main
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits for the FTP input
+const (
+	ftpMaxUpload   = 64 << 20 // bytes accepted per file
+	ftpIdleTimeout = 5 * time.Minute
+	ftpDataTimeout = 30 * time.Second
+)
+
+// FTPServer accepts ZPL uploaded over FTP, the way Zebra printers do. Every stored file is
+// printed as a job, to the destination of the first FTP route matching the login user and
+// upload directory, or else through the routing rules and print mode. Nothing is kept.
+type FTPServer struct {
+	listener net.Listener
+	quit     chan any
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	conns map[net.Conn]bool
+}
+
+// FTPRoute sends uploads from a login user, into a directory, or both to their own
+// destination. Empty fields match anything; routes are tried in ID order.
+type FTPRoute struct {
+	RouteID   int    `json:"routeID"`
+	User      string `json:"user"`
+	Directory string `json:"directory"` // Upload directory, including its subdirectories
+	Action    string `json:"action"`    // printer, group or emulate
+	TargetID  int    `json:"targetID"`  // Printer or relay group ID
+}
+
+// ftpSession is the state of one control connection
+type ftpSession struct {
+	app      *App
+	conn     net.Conn
+	reader   *bufio.Reader
+	user     string
+	loggedIn bool
+	cwd      string
+
+	// passive is the listener opened by PASV/EPSV; active is the address given by PORT
+	passive net.Listener
+	active  string
+}
+
+// Initialize ftp_routes table
+func InitFTPRoutesTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS ftp_routes (
+			routeID INTEGER PRIMARY KEY AUTOINCREMENT,
+			user TEXT DEFAULT '',
+			directory TEXT DEFAULT '',
+			action TEXT NOT NULL,
+			targetID INTEGER DEFAULT 0
+		)`)
+	if err != nil {
+		println("Error initializing ftp_routes table:", err.Error())
+	}
+	return err
+}
+
+// validate checks a route and cleans its directory before it is saved
+func (r *FTPRoute) validate() error {
+	if r.User == "" && r.Directory == "" {
+		return fmt.Errorf("FTP route needs a user or a directory")
+	}
+	if r.Directory != "" {
+		r.Directory = path.Clean("/" + r.Directory)
+	}
+	return validateFixedRoute("FTP route", r.Action, r.TargetID)
+}
+
+// matches reports whether a file uploaded by user to file (a full FTP path) uses the route
+func (r *FTPRoute) matches(user string, file string) bool {
+	if r.User != "" && r.User != user {
+		return false
+	}
+	if r.Directory != "" && r.Directory != "/" {
+		dir := path.Dir(file)
+		if dir != r.Directory && !strings.HasPrefix(dir, r.Directory+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// AddFTPRoute saves an FTP route and returns its ID
+func AddFTPRoute(db *sql.DB, r FTPRoute) (int, error) {
+	if err := r.validate(); err != nil {
+		return 0, err
+	}
+	res, err := db.Exec(`INSERT INTO ftp_routes (user, directory, action, targetID) VALUES (?, ?, ?, ?)`,
+		r.User, r.Directory, r.Action, r.TargetID)
+	if err != nil {
+		println("Error adding FTP route:", err.Error())
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// UpdateFTPRoute saves an FTP route's user, directory and destination
+func UpdateFTPRoute(db *sql.DB, r FTPRoute) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	_, err := db.Exec(`UPDATE ftp_routes SET user=?, directory=?, action=?, targetID=? WHERE routeID=?`,
+		r.User, r.Directory, r.Action, r.TargetID, r.RouteID)
+	if err != nil {
+		println("Error updating FTP route:", err.Error())
+	}
+	return err
+}
+
+// GetFTPRoutes returns all FTP routes in the order they are tried
+func GetFTPRoutes(db *sql.DB) ([]FTPRoute, error) {
+	rows, err := db.Query(`SELECT routeID, COALESCE(user, ''), COALESCE(directory, ''), action, COALESCE(targetID, 0) FROM ftp_routes ORDER BY routeID`)
+	if err != nil {
+		println("Error getting FTP routes:", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+	var routes []FTPRoute
+	for rows.Next() {
+		var r FTPRoute
+		if err := rows.Scan(&r.RouteID, &r.User, &r.Directory, &r.Action, &r.TargetID); err != nil {
+			println("Error scanning FTP route row:", err.Error())
+			continue
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// DeleteFTPRoute removes an FTP route
+func DeleteFTPRoute(db *sql.DB, routeID int) error {
+	_, err := db.Exec(`DELETE FROM ftp_routes WHERE routeID=?`, routeID)
+	if err != nil {
+		println("Error deleting FTP route:", err.Error())
+	}
+	return err
+}
+
+// NewFTPServer starts the FTP input on the configured port
+func (a *App) NewFTPServer() (*FTPServer, error) {
+	port := a.Settings.FTPPort
+	if port == 0 {
+		port = 21
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort(CONN_HOST, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start FTP server: %w", err)
+	}
+	s := &FTPServer{listener: l, quit: make(chan any), conns: map[net.Conn]bool{}}
+	s.wg.Add(1)
+	go a.serveFTP(s)
+
+	fmt.Printf("FTP server listening on %s\n", l.Addr().String())
+	return s, nil
+}
+
+// Stop closes the listener and any open sessions
+func (s *FTPServer) Stop() {
+	close(s.quit)
+	s.listener.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	waitTimeout(&s.wg, 5*time.Second)
+}
+
+func (a *App) serveFTP(s *FTPServer) {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+				log.Println("FTP accept error", err)
+				continue
+			}
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func(c net.Conn) {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, c)
+				s.mu.Unlock()
+				c.Close()
+			}()
+			session := &ftpSession{app: a, conn: c, reader: bufio.NewReader(c), cwd: "/"}
+			session.run()
+		}(conn)
+	}
+}
+
+func (f *ftpSession) reply(code int, message string) {
+	fmt.Fprintf(f.conn, "%d %s\r\n", code, message)
+}
+
+func (f *ftpSession) run() {
+	defer f.closeData()
+	f.reply(220, "ZPL Printer Emulator FTP ready")
+	for {
+		f.conn.SetReadDeadline(time.Now().Add(ftpIdleTimeout))
+		line, err := f.reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd, arg, _ := strings.Cut(line, " ")
+		cmd = strings.ToUpper(cmd)
+
+		if !f.loggedIn {
+			switch cmd {
+			case "USER", "PASS", "QUIT", "SYST", "FEAT", "OPTS", "NOOP":
+			default:
+				f.reply(530, "Please login with USER and PASS")
+				continue
+			}
+		}
+
+		switch cmd {
+		case "USER":
+			f.user = arg
+			f.loggedIn = false
+			f.reply(331, "Password required")
+		case "PASS":
+			if f.checkLogin(f.user, arg) {
+				f.loggedIn = true
+				f.reply(230, "Logged in")
+			} else {
+				f.reply(530, "Login incorrect")
+			}
+		case "QUIT":
+			f.reply(221, "Goodbye")
+			return
+		case "SYST":
+			f.reply(215, "UNIX Type: L8")
+		case "FEAT":
+			fmt.Fprintf(f.conn, "211-Features:\r\n EPSV\r\n PASV\r\n UTF8\r\n211 End\r\n")
+		case "OPTS":
+			f.reply(200, "OK")
+		case "NOOP":
+			f.reply(200, "OK")
+		case "PWD", "XPWD":
+			f.reply(257, strconv.Quote(f.cwd)+" is the current directory")
+		case "CWD", "XCWD":
+			// There are no real directories; remember the path so clients that change
+			// into a folder before uploading are happy
+			if strings.HasPrefix(arg, "/") {
+				f.cwd = path.Clean(arg)
+			} else {
+				f.cwd = path.Join(f.cwd, arg)
+			}
+			f.reply(250, "Directory changed")
+		case "CDUP", "XCUP":
+			f.cwd = path.Dir(f.cwd)
+			f.reply(250, "Directory changed")
+		case "TYPE", "MODE", "STRU":
+			f.reply(200, "OK")
+		case "PASV":
+			f.enterPassive(false)
+		case "EPSV":
+			f.enterPassive(true)
+		case "PORT":
+			f.setActive(arg)
+		case "STOR", "APPE", "STOU":
+			f.store(arg)
+		case "LIST", "NLST", "MLSD":
+			f.list()
+		case "SIZE", "MDTM", "RETR", "DELE", "RNFR", "RNTO", "MKD", "RMD":
+			f.reply(550, "Files are printed, not stored")
+		default:
+			f.reply(502, "Command not implemented")
+		}
+	}
+}
+
+// checkLogin accepts anyone when no user is configured, otherwise the configured user and password
+func (f *ftpSession) checkLogin(user string, password string) bool {
+	settings := f.app.Settings
+	if settings.FTPUser == "" {
+		return true
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(settings.FTPUser)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(password), []byte(settings.FTPPassword)) == 1
+	return userOK && passOK
+}
+
+// enterPassive opens a data listener on the address the client connected to
+func (f *ftpSession) enterPassive(extended bool) {
+	f.closeData()
+	host, _, _ := net.SplitHostPort(f.conn.LocalAddr().String())
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		f.reply(425, "Can't open data connection")
+		return
+	}
+	f.passive = l
+	port := l.Addr().(*net.TCPAddr).Port
+	if extended {
+		f.reply(229, fmt.Sprintf("Entering Extended Passive Mode (|||%d|)", port))
+		return
+	}
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		f.closeData()
+		f.reply(425, "Use EPSV for IPv6 connections")
+		return
+	}
+	f.reply(227, fmt.Sprintf("Entering Passive Mode (%d,%d,%d,%d,%d,%d)", ip[0], ip[1], ip[2], ip[3], port>>8, port&0xff))
+}
+
+// setActive records the h1,h2,h3,h4,p1,p2 address from a PORT command
+func (f *ftpSession) setActive(arg string) {
+	f.closeData()
+	parts := strings.Split(arg, ",")
+	if len(parts) != 6 {
+		f.reply(501, "Invalid PORT command")
+		return
+	}
+	nums := make([]int, 6)
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || n < 0 || n > 255 {
+			f.reply(501, "Invalid PORT command")
+			return
+		}
+		nums[i] = n
+	}
+	ip := fmt.Sprintf("%d.%d.%d.%d", nums[0], nums[1], nums[2], nums[3])
+	// Only connect back to the client itself
+	remote, _, _ := net.SplitHostPort(f.conn.RemoteAddr().String())
+	if !net.ParseIP(ip).Equal(net.ParseIP(remote)) {
+		f.reply(501, "PORT address must match the client")
+		return
+	}
+	f.active = net.JoinHostPort(ip, strconv.Itoa(nums[4]<<8|nums[5]))
+	f.reply(200, "PORT command successful")
+}
+
+// openData connects the data channel set up by the last PASV, EPSV or PORT
+func (f *ftpSession) openData() (net.Conn, error) {
+	defer f.closeData()
+	switch {
+	case f.passive != nil:
+		if tl, ok := f.passive.(*net.TCPListener); ok {
+			tl.SetDeadline(time.Now().Add(ftpDataTimeout))
+		}
+		// Only take the data connection from the client itself, so another host can't
+		// slip its own data into the upload
+		client, _, _ := net.SplitHostPort(f.conn.RemoteAddr().String())
+		for {
+			c, err := f.passive.Accept()
+			if err != nil {
+				return nil, err
+			}
+			peer, _, _ := net.SplitHostPort(c.RemoteAddr().String())
+			if net.ParseIP(peer).Equal(net.ParseIP(client)) {
+				return c, nil
+			}
+			log.Println("FTP data connection from", peer, "refused, expected", client)
+			c.Close()
+		}
+	case f.active != "":
+		return net.DialTimeout("tcp", f.active, ftpDataTimeout)
+	}
+	return nil, fmt.Errorf("no data connection, use PASV or PORT first")
+}
+
+func (f *ftpSession) closeData() {
+	if f.passive != nil {
+		f.passive.Close()
+		f.passive = nil
+	}
+	f.active = ""
+}
+
+// list sends an empty listing; uploaded files are not kept
+func (f *ftpSession) list() {
+	data, err := f.openData()
+	if err != nil {
+		f.reply(425, err.Error())
+		return
+	}
+	f.reply(150, "Here comes the directory listing")
+	data.Close()
+	f.reply(226, "Directory send OK")
+}
+
+// store reads an uploaded file and prints it as a job
+func (f *ftpSession) store(name string) {
+	data, err := f.openData()
+	if err != nil {
+		f.reply(425, err.Error())
+		return
+	}
+	f.reply(150, "Ok to send data")
+	data.SetReadDeadline(time.Now().Add(ftpDataTimeout))
+	body, err := io.ReadAll(io.LimitReader(data, ftpMaxUpload+1))
+	data.Close()
+	if err != nil {
+		f.reply(426, "Transfer aborted: "+err.Error())
+		return
+	}
+	if len(body) > ftpMaxUpload {
+		f.reply(552, "File is too large")
+		return
+	}
+
+	EmulatorStats.JobStarted()
+	mode, _, _ := printSelection()
+	file := path.Join(f.cwd, name)
+	job := &Job{
+		ReceivedAt:    time.Now(),
+		SourceAddress: f.conn.RemoteAddr().String(),
+		Listener:      "ftp://" + f.conn.LocalAddr().String() + file,
+		Mode:          printModeName(mode),
+	}
+	var jobErr error
+	if len(body) == 0 {
+		jobErr = fmt.Errorf("file is empty")
+	} else {
+		jobErr = f.app.processJob(job, string(body), f.route(file))
+	}
+	EmulatorStats.JobFinished(len(body), jobErr)
+
+	if jobErr != nil {
+		f.reply(451, "Print failed: "+jobErr.Error())
+		return
+	}
+	f.reply(226, fmt.Sprintf("Printed as job %d", job.JobID))
+}
+
+// route returns the destination of the first FTP route matching the upload, or nil to use
+// the routing rules and print mode
+func (f *ftpSession) route(file string) *RouteDecision {
+	routes, err := GetFTPRoutes(f.app.db)
+	if err != nil {
+		fmt.Println("Error loading FTP routes:", err)
+		return nil
+	}
+	for _, r := range routes {
+		if r.matches(f.user, file) {
+			return fixedRoute(fmt.Sprintf("FTP route %d", r.RouteID), r.Action, r.TargetID)
+		}
+	}
+	return nil
+}
+
+// startFTP starts the FTP input if it is enabled
+func (a *App) startFTP() {
+	if !a.Settings.FTPEnabled || a.ftp != nil {
+		return
+	}
+	s, err := a.NewFTPServer()
+	if err != nil {
+		fmt.Println("Error starting FTP server:", err)
+		a.ui.Error("Error Starting FTP Server", err.Error())
+		return
+	}
+	a.ftp = s
+}
+
+// stopFTP stops the FTP input
+func (a *App) stopFTP() {
+	if a.ftp != nil {
+		a.ftp.Stop()
+		a.ftp = nil
+	}
+}
+
+// restartFTP applies changed FTP settings; the server runs while the printer server is running
+func (a *App) restartFTP() {
+	a.stopFTP()
+	if Running {
+		a.startFTP()
+	}
+}
+
+// SetFTPEnabled turns the FTP input on or off
+func (a *App) SetFTPEnabled(enabled bool) error {
+	if err := a.updateSettings(func(s *Settings) { s.FTPEnabled = enabled }); err != nil {
+		return err
+	}
+	a.restartFTP()
+	return nil
+}
+
+// GetFTPEnabled returns whether the FTP input is enabled
+func (a *App) GetFTPEnabled() bool {
+	return a.Settings.FTPEnabled
+}
+
+// UpdateFTPPort changes the FTP port, restarting the server if running
+func (a *App) UpdateFTPPort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port %d is out of range", port)
+	}
+	if err := a.updateSettings(func(s *Settings) { s.FTPPort = port }); err != nil {
+		return err
+	}
+	a.restartFTP()
+	return nil
+}
+
+func (a *App) GetFTPPort() int {
+	return a.Settings.FTPPort
+}
+
+// SetFTPCredentials sets the FTP login; an empty user allows anonymous uploads
+func (a *App) SetFTPCredentials(user string, password string) error {
+	return a.updateSettings(func(s *Settings) {
+		s.FTPUser = user
+		s.FTPPassword = password
+	})
+}
+
+// GetFTPUser returns the FTP login user, or "" when anonymous uploads are allowed
+func (a *App) GetFTPUser() string {
+	return a.Settings.FTPUser
+}
+
+// FTP route methods for Wails frontend
+func (a *App) AddFTPRoute(route FTPRoute) (int, error) {
+	return AddFTPRoute(a.db, route)
+}
+
+func (a *App) UpdateFTPRoute(route FTPRoute) error {
+	return UpdateFTPRoute(a.db, route)
+}
+
+func (a *App) GetFTPRoutes() ([]FTPRoute, error) {
+	return GetFTPRoutes(a.db)
+}
+
+func (a *App) DeleteFTPRoute(routeID int) error {
+	return DeleteFTPRoute(a.db, routeID)
+}
@@ -4,7 +4,10 @@ go 1.23.0
 
 toolchain go1.24.2
 
-require github.com/wailsapp/wails/v2 v2.10.1
+require (
+	github.com/wailsapp/wails/v2 v2.10.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
@@ -41,9 +44,9 @@ require (
 	github.com/wailsapp/go-webview2 v1.0.21 // indirect
 	github.com/wailsapp/mimetype v1.4.1 // indirect
 	golang.org/x/crypto v0.37.0 // indirect
-	golang.org/x/net v0.39.0 // indirect
+	golang.org/x/net v0.39.0
 	golang.org/x/sys v0.34.0
-	golang.org/x/text v0.24.0 // indirect
+	golang.org/x/text v0.24.0
 	modernc.org/sqlite v1.37.0
 )
 
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runHeadless runs the emulator without the Wails window: it starts the listeners from the
+// saved settings, logs to stdout and shuts down cleanly on SIGINT or SIGTERM.
+func runHeadless(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("headless", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	save := fs.Bool("save", false, "save rendered labels to the configured print path")
+	configPath := fs.String("config", os.Getenv(configPathEnv), "config file applied over the saved settings and reloaded when it changes")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	log.SetOutput(stdout)
+
+	db, err := openAppDatabase()
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	defer db.SQL.Close()
+
+	app := NewApp(db.SQL)
+	app.ui = newLogNotifier(stdout)
+	app.ctx = context.Background()
+	SaveToFile = *save && app.Settings.PrintPath != ""
+	app.startConfig(*configPath)
+	for _, v := range app.GetConfigReport().Values {
+		if v.Error != "" {
+			log.Printf("Config %s=%q from %s ignored: %s", v.Key, v.Value, v.Source, v.Error)
+		} else if v.Source == ConfigSourceFile || v.Source == ConfigSourceEnv {
+			log.Printf("Config %s=%q from %s", v.Key, v.Value, v.Source)
+		}
+	}
+	if err := app.GetConfigReport().Error; err != "" {
+		log.Println("Config error:", err)
+	}
+
+	app.startJanitor()
+	if app.Settings.SNMPEnabled {
+		app.startSNMP()
+	}
+	app.StartPrinterServer()
+	if !Running {
+		app.shutdown(app.ctx)
+		return 1
+	}
+	log.Printf("Printer emulator %s listening on %s:%d", AppVersion, CONN_HOST, int(app.Settings.PrinterPort))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	sig := <-signals
+	log.Printf("Received %s, shutting down", sig)
+
+	app.StopPrintServer()
+	app.shutdown(app.ctx)
+	return 0
+}
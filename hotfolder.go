@@ -0,0 +1,389 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How often the hot folder is scanned for new files
+const hotFolderPollInterval = 1 * time.Second
+
+// Subfolders that files are moved to once they have been handled
+const (
+	hotFolderProcessed = "processed"
+	hotFolderFailed    = "failed"
+)
+
+// hotFolderExtensions are the file types picked up from the hot folder
+var hotFolderExtensions = map[string]bool{".zpl": true, ".prn": true, ".txt": true}
+
+// HotFolderResult is written next to each handled file as <file>.result.json
+type HotFolderResult struct {
+	File        string       `json:"file"`
+	JobID       int          `json:"jobID"`
+	Status      string       `json:"status"`
+	Error       string       `json:"error"`
+	Mode        string       `json:"mode"`
+	Outcomes    []JobOutcome `json:"outcomes"`
+	ProcessedAt time.Time    `json:"processedAt"`
+}
+
+// HotFolderRoute maps a folder to its own destination, so files dropped into it skip the
+// routing rules and the print mode. The hot folder in the settings still follows them.
+type HotFolderRoute struct {
+	FolderID int    `json:"folderID"`
+	Path     string `json:"path"`
+	Enabled  bool   `json:"enabled"`
+	Action   string `json:"action"`   // printer, group or emulate
+	TargetID int    `json:"targetID"` // Printer or relay group ID
+}
+
+// HotFolder watches a directory and prints every file dropped into it
+type HotFolder struct {
+	dir   string
+	route *HotFolderRoute // nil for the settings' hot folder
+	quit  chan any
+	wg    sync.WaitGroup
+}
+
+// fileStamp identifies a version of a file; a file is only read once it stops changing
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Initialize hot_folders table
+func InitHotFoldersTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS hot_folders (
+			folderID INTEGER PRIMARY KEY AUTOINCREMENT,
+			path TEXT NOT NULL,
+			enabled INTEGER DEFAULT 1,
+			action TEXT NOT NULL,
+			targetID INTEGER DEFAULT 0
+		)`)
+	if err != nil {
+		println("Error initializing hot_folders table:", err.Error())
+	}
+	return err
+}
+
+// validate checks a folder mapping before it is saved
+func (f *HotFolderRoute) validate() error {
+	if strings.TrimSpace(f.Path) == "" {
+		return fmt.Errorf("hot folder needs a path")
+	}
+	return validateFixedRoute("hot folder "+f.Path, f.Action, f.TargetID)
+}
+
+// AddHotFolderRoute saves a folder mapping and returns its ID
+func AddHotFolderRoute(db *sql.DB, f HotFolderRoute) (int, error) {
+	if err := f.validate(); err != nil {
+		return 0, err
+	}
+	enabledInt := 0
+	if f.Enabled {
+		enabledInt = 1
+	}
+	res, err := db.Exec(`INSERT INTO hot_folders (path, enabled, action, targetID) VALUES (?, ?, ?, ?)`,
+		f.Path, enabledInt, f.Action, f.TargetID)
+	if err != nil {
+		println("Error adding hot folder:", err.Error())
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// UpdateHotFolderRoute saves a folder mapping's path and destination
+func UpdateHotFolderRoute(db *sql.DB, f HotFolderRoute) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	enabledInt := 0
+	if f.Enabled {
+		enabledInt = 1
+	}
+	_, err := db.Exec(`UPDATE hot_folders SET path=?, enabled=?, action=?, targetID=? WHERE folderID=?`,
+		f.Path, enabledInt, f.Action, f.TargetID, f.FolderID)
+	if err != nil {
+		println("Error updating hot folder:", err.Error())
+	}
+	return err
+}
+
+// GetHotFolderRoutes returns all folder mappings
+func GetHotFolderRoutes(db *sql.DB) ([]HotFolderRoute, error) {
+	rows, err := db.Query(`SELECT folderID, path, COALESCE(enabled, 1), action, COALESCE(targetID, 0) FROM hot_folders ORDER BY folderID`)
+	if err != nil {
+		println("Error getting hot folders:", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+	var folders []HotFolderRoute
+	for rows.Next() {
+		var f HotFolderRoute
+		var enabledInt int
+		if err := rows.Scan(&f.FolderID, &f.Path, &enabledInt, &f.Action, &f.TargetID); err != nil {
+			println("Error scanning hot folder row:", err.Error())
+			continue
+		}
+		f.Enabled = enabledInt != 0
+		folders = append(folders, f)
+	}
+	return folders, nil
+}
+
+// DeleteHotFolderRoute removes a folder mapping; the folder and its files are left alone
+func DeleteHotFolderRoute(db *sql.DB, folderID int) error {
+	_, err := db.Exec(`DELETE FROM hot_folders WHERE folderID=?`, folderID)
+	if err != nil {
+		println("Error deleting hot folder:", err.Error())
+	}
+	return err
+}
+
+// NewHotFolder starts watching dir, creating it and its processed/failed folders if needed.
+// Files go to route's destination, or follow the routing rules when route is nil.
+func (a *App) NewHotFolder(dir string, route *HotFolderRoute) (*HotFolder, error) {
+	for _, d := range []string{dir, filepath.Join(dir, hotFolderProcessed), filepath.Join(dir, hotFolderFailed)} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, fmt.Errorf("error creating hot folder: %w", err)
+		}
+	}
+	h := &HotFolder{dir: dir, route: route, quit: make(chan any)}
+	h.wg.Add(1)
+	go a.watchHotFolder(h)
+	return h, nil
+}
+
+// Stop stops watching; a file being printed is allowed to finish
+func (h *HotFolder) Stop() {
+	close(h.quit)
+	waitTimeout(&h.wg, 5*time.Second)
+}
+
+func (a *App) watchHotFolder(h *HotFolder) {
+	defer h.wg.Done()
+	seen := map[string]fileStamp{}
+	ticker := time.NewTicker(hotFolderPollInterval)
+	defer ticker.Stop()
+	for {
+		entries, err := os.ReadDir(h.dir)
+		if err != nil {
+			fmt.Println("Error reading hot folder:", err)
+		}
+		current := map[string]fileStamp{}
+		for _, entry := range entries {
+			if entry.IsDir() || !hotFolderExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			stamp := fileStamp{size: info.Size(), modTime: info.ModTime()}
+			// Wait for a second scan with the same size and time so half-written files are skipped
+			if seen[entry.Name()] != stamp {
+				current[entry.Name()] = stamp
+				continue
+			}
+			select {
+			case <-h.quit:
+				return
+			default:
+			}
+			a.processHotFolderFile(h, entry.Name())
+		}
+		seen = current
+
+		select {
+		case <-h.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processHotFolderFile prints one file to the folder's destination, or through the routing
+// rules and print mode, then moves it to the processed or failed folder with a sidecar result file
+func (a *App) processHotFolderFile(h *HotFolder, name string) {
+	dir := h.dir
+	path := filepath.Join(dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Println("Error reading hot folder file:", err)
+		return
+	}
+
+	EmulatorStats.JobStarted()
+	mode, _, _ := printSelection()
+	job := &Job{
+		ReceivedAt:    time.Now(),
+		SourceAddress: name,
+		Listener:      "folder://" + dir,
+		Mode:          printModeName(mode),
+	}
+	var route *RouteDecision
+	if h.route != nil {
+		route = fixedRoute("hot folder "+dir, h.route.Action, h.route.TargetID)
+	}
+	var jobErr error
+	if len(data) == 0 {
+		jobErr = fmt.Errorf("file is empty")
+	} else {
+		jobErr = a.processJob(job, string(data), route)
+	}
+	EmulatorStats.JobFinished(len(data), jobErr)
+
+	result := HotFolderResult{
+		File:        name,
+		JobID:       job.JobID,
+		Status:      JobStatusCompleted,
+		Mode:        job.Mode,
+		Outcomes:    job.Outcomes,
+		ProcessedAt: time.Now(),
+	}
+	target := hotFolderProcessed
+	if jobErr != nil {
+		result.Status = JobStatusFailed
+		result.Error = jobErr.Error()
+		target = hotFolderFailed
+	}
+
+	moved, err := moveToFolder(path, filepath.Join(dir, target))
+	if err != nil {
+		// Leave a failed file where it is rather than printing it again on the next scan
+		fmt.Println("Error moving hot folder file:", err)
+		os.Rename(path, path+".error")
+		return
+	}
+	sidecar, err := json.MarshalIndent(result, "", "  ")
+	if err == nil {
+		err = os.WriteFile(moved+".result.json", sidecar, 0644)
+	}
+	if err != nil {
+		fmt.Println("Error writing hot folder result:", err)
+	}
+}
+
+// moveToFolder moves a file into dir, adding a timestamp if a file of that name is already there
+func moveToFolder(path string, dir string) (string, error) {
+	target := filepath.Join(dir, filepath.Base(path))
+	if _, err := os.Stat(target); err == nil {
+		ext := filepath.Ext(path)
+		base := strings.TrimSuffix(filepath.Base(path), ext)
+		target = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, time.Now().UnixNano(), ext))
+	}
+	if err := os.Rename(path, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// startHotFolder starts watching the hot folder in the settings and every enabled mapped
+// folder. A folder is only watched once, by the first of them that names it.
+func (a *App) startHotFolder() {
+	if len(a.hotFolders) > 0 {
+		return
+	}
+	var folders []HotFolderRoute
+	if a.Settings.HotFolderPath != "" {
+		folders = append(folders, HotFolderRoute{Path: a.Settings.HotFolderPath, Enabled: true})
+	}
+	routes, err := GetHotFolderRoutes(a.db)
+	if err != nil {
+		fmt.Println("Error loading hot folders:", err)
+	}
+	folders = append(folders, routes...)
+
+	watched := map[string]bool{}
+	for i := range folders {
+		f := &folders[i]
+		dir := filepath.Clean(f.Path)
+		if !f.Enabled || watched[dir] {
+			continue
+		}
+		var route *HotFolderRoute
+		if f.FolderID != 0 {
+			route = f
+		}
+		h, err := a.NewHotFolder(dir, route)
+		if err != nil {
+			fmt.Println("Error starting hot folder:", err)
+			a.ui.Error("Error Starting Hot Folder", err.Error())
+			continue
+		}
+		watched[dir] = true
+		a.hotFolders = append(a.hotFolders, h)
+	}
+}
+
+// stopHotFolder stops watching every hot folder
+func (a *App) stopHotFolder() {
+	for _, h := range a.hotFolders {
+		h.Stop()
+	}
+	a.hotFolders = nil
+}
+
+// restartHotFolders picks up changed folders while the printer server is running
+func (a *App) restartHotFolders() {
+	a.stopHotFolder()
+	if Running {
+		a.startHotFolder()
+	}
+}
+
+// SetHotFolderPath sets the directory watched for print files; an empty path turns it off.
+// The folder is watched while the printer server is running.
+func (a *App) SetHotFolderPath(path string) error {
+	if err := a.updateSettings(func(s *Settings) { s.HotFolderPath = path }); err != nil {
+		return err
+	}
+	a.restartHotFolders()
+	return nil
+}
+
+// GetHotFolderPath returns the directory watched for print files
+func (a *App) GetHotFolderPath() string {
+	return a.Settings.HotFolderPath
+}
+
+// Hot folder mapping methods for Wails frontend
+
+// AddHotFolder watches another folder whose files go to their own printer, relay group or the emulator
+func (a *App) AddHotFolder(folder HotFolderRoute) (int, error) {
+	id, err := AddHotFolderRoute(a.db, folder)
+	if err != nil {
+		return 0, err
+	}
+	a.restartHotFolders()
+	return id, nil
+}
+
+func (a *App) UpdateHotFolder(folder HotFolderRoute) error {
+	if err := UpdateHotFolderRoute(a.db, folder); err != nil {
+		return err
+	}
+	a.restartHotFolders()
+	return nil
+}
+
+func (a *App) GetHotFolders() ([]HotFolderRoute, error) {
+	return GetHotFolderRoutes(a.db)
+}
+
+func (a *App) DeleteHotFolder(folderID int) error {
+	if err := DeleteHotFolderRoute(a.db, folderID); err != nil {
+		return err
+	}
+	a.restartHotFolders()
+	return nil
+}
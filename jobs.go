@@ -0,0 +1,313 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Job is a print job received by one of the emulator's listeners
+type Job struct {
+	JobID         int          `json:"jobID"`
+	ReceivedAt    time.Time    `json:"receivedAt"`
+	CompletedAt   time.Time    `json:"completedAt"`
+	SourceAddress string       `json:"sourceAddress"`
+	Listener      string       `json:"listener"`
+	Mode          string       `json:"mode"`
+	ByteCount     int          `json:"byteCount"`
+	LabelCount    int          `json:"labelCount"`
+	Status        string       `json:"status"`
+	Error         string       `json:"error"`
+	RawData       []byte       `json:"rawData,omitempty"`
+	Images        [][]byte     `json:"images,omitempty"`
+	Outcomes      []JobOutcome `json:"outcomes,omitempty"`
+}
+
+// JobOutcome is the result of delivering a job to one printer
+type JobOutcome struct {
+	PrinterID   int       `json:"printerID"`
+	PrinterName string    `json:"printerName"`
+	Success     bool      `json:"success"`
+	Error       string    `json:"error"`
+	SentAt      time.Time `json:"sentAt"`
+	LatencyMs   int64     `json:"latencyMs"`
+	FailedOver  bool      `json:"failedOver"` // Failed, but a later printer in a failover group took the job
+}
+
+// failed reports whether this outcome means the job was not delivered
+func (o JobOutcome) failed() bool {
+	return !o.Success && !o.FailedOver
+}
+
+// JobPage is one page of the job history, newest first
+type JobPage struct {
+	Jobs  []Job `json:"jobs"`
+	Total int   `json:"total"`
+}
+
+// Job status values
+const (
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusDropped   = "dropped" // A routing rule discarded the job
+)
+
+// printModeName maps the PrintMode global to the name stored with each job
+func printModeName(mode int) string {
+	switch mode {
+	case 0:
+		return "emulate"
+	case 1:
+		return "forward"
+	case 2:
+		return "relay"
+	default:
+		return "unknown"
+	}
+}
+
+// newJobOutcome builds the delivery result for a printer
+func newJobOutcome(p Printer, err error) JobOutcome {
+	o := JobOutcome{
+		PrinterID:   p.PrinterID,
+		PrinterName: p.PrinterName,
+		Success:     err == nil,
+		SentAt:      time.Now(),
+	}
+	if err != nil {
+		o.Error = err.Error()
+	}
+	return o
+}
+
+// Initialize jobs, job_images and job_outcomes tables
+func InitJobsTables(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS jobs (
+			jobID INTEGER PRIMARY KEY AUTOINCREMENT,
+			receivedAt INTEGER NOT NULL,
+			completedAt INTEGER,
+			sourceAddress TEXT,
+			listener TEXT,
+			mode TEXT,
+			byteCount INTEGER DEFAULT 0,
+			labelCount INTEGER DEFAULT 0,
+			status TEXT,
+			error TEXT,
+			rawData BLOB
+		)`)
+	if err != nil {
+		println("Error initializing jobs table:", err.Error())
+		return err
+	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS job_images (
+			imageID INTEGER PRIMARY KEY AUTOINCREMENT,
+			jobID INTEGER NOT NULL,
+			labelIndex INTEGER NOT NULL,
+			png BLOB
+		)`)
+	if err != nil {
+		println("Error initializing job_images table:", err.Error())
+		return err
+	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS job_outcomes (
+			outcomeID INTEGER PRIMARY KEY AUTOINCREMENT,
+			jobID INTEGER NOT NULL,
+			printerID INTEGER,
+			printerName TEXT,
+			success INTEGER DEFAULT 0,
+			error TEXT,
+			sentAt INTEGER,
+			latencyMs INTEGER DEFAULT 0,
+			failedOver INTEGER DEFAULT 0
+		)`)
+	if err != nil {
+		println("Error initializing job_outcomes table:", err.Error())
+		return err
+	}
+	db.Exec(`ALTER TABLE job_outcomes ADD COLUMN latencyMs INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE job_outcomes ADD COLUMN failedOver INTEGER DEFAULT 0`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_jobs_receivedAt ON jobs(receivedAt)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_job_images_jobID ON job_images(jobID)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_job_outcomes_jobID ON job_outcomes(jobID)`)
+	return nil
+}
+
+// AddJob stores a job with its images and delivery outcomes
+func AddJob(db *sql.DB, j *Job) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO jobs (receivedAt, completedAt, sourceAddress, listener, mode, byteCount, labelCount, status, error, rawData)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, j.ReceivedAt.UnixMilli(), j.CompletedAt.UnixMilli(), j.SourceAddress, j.Listener, j.Mode, j.ByteCount, j.LabelCount, j.Status, j.Error, j.RawData)
+	if err != nil {
+		println("Error adding job:", err.Error())
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	j.JobID = int(id)
+
+	for i, png := range j.Images {
+		_, err = tx.Exec(`INSERT INTO job_images (jobID, labelIndex, png) VALUES (?, ?, ?)`, j.JobID, i, png)
+		if err != nil {
+			println("Error adding job image:", err.Error())
+			return err
+		}
+	}
+	for _, o := range j.Outcomes {
+		successInt := 0
+		if o.Success {
+			successInt = 1
+		}
+		failedOverInt := 0
+		if o.FailedOver {
+			failedOverInt = 1
+		}
+		_, err = tx.Exec(`
+			INSERT INTO job_outcomes (jobID, printerID, printerName, success, error, sentAt, latencyMs, failedOver)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, j.JobID, o.PrinterID, o.PrinterName, successInt, o.Error, o.SentAt.UnixMilli(), o.LatencyMs, failedOverInt)
+		if err != nil {
+			println("Error adding job outcome:", err.Error())
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetJobs returns a page of jobs, newest first, without raw data or images
+func GetJobs(db *sql.DB, offset int, limit int) (*JobPage, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	page := &JobPage{}
+	err := db.QueryRow(`SELECT COUNT(*) FROM jobs`).Scan(&page.Total)
+	if err != nil {
+		println("Error counting jobs:", err.Error())
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT jobID, receivedAt, COALESCE(completedAt, 0), COALESCE(sourceAddress, ''), COALESCE(listener, ''), COALESCE(mode, ''),
+			COALESCE(byteCount, 0), COALESCE(labelCount, 0), COALESCE(status, ''), COALESCE(error, '')
+		FROM jobs ORDER BY jobID DESC LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		println("Error getting jobs:", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			println("Error scanning job row:", err.Error())
+			continue
+		}
+		page.Jobs = append(page.Jobs, *j)
+	}
+	return page, nil
+}
+
+// GetJobByID returns a job with its raw data, images and outcomes
+func GetJobByID(db *sql.DB, jobID int) (*Job, error) {
+	row := db.QueryRow(`
+		SELECT jobID, receivedAt, COALESCE(completedAt, 0), COALESCE(sourceAddress, ''), COALESCE(listener, ''), COALESCE(mode, ''),
+			COALESCE(byteCount, 0), COALESCE(labelCount, 0), COALESCE(status, ''), COALESCE(error, ''), rawData
+		FROM jobs WHERE jobID = ?
+	`, jobID)
+	var j Job
+	var receivedAt, completedAt int64
+	err := row.Scan(&j.JobID, &receivedAt, &completedAt, &j.SourceAddress, &j.Listener, &j.Mode, &j.ByteCount, &j.LabelCount, &j.Status, &j.Error, &j.RawData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, err
+	}
+	j.ReceivedAt = time.UnixMilli(receivedAt)
+	j.CompletedAt = time.UnixMilli(completedAt)
+
+	j.Images, err = GetJobImages(db, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT COALESCE(printerID, 0), COALESCE(printerName, ''), success, COALESCE(error, ''), COALESCE(sentAt, 0), COALESCE(latencyMs, 0), COALESCE(failedOver, 0)
+		FROM job_outcomes WHERE jobID = ? ORDER BY outcomeID
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o JobOutcome
+		var successInt int
+		var failedOverInt int
+		var sentAt int64
+		if err := rows.Scan(&o.PrinterID, &o.PrinterName, &successInt, &o.Error, &sentAt, &o.LatencyMs, &failedOverInt); err != nil {
+			println("Error scanning job outcome row:", err.Error())
+			continue
+		}
+		o.Success = successInt != 0
+		o.FailedOver = failedOverInt != 0
+		o.SentAt = time.UnixMilli(sentAt)
+		j.Outcomes = append(j.Outcomes, o)
+	}
+	return &j, nil
+}
+
+// GetJobImages returns the rendered PNGs of a job in label order
+func GetJobImages(db *sql.DB, jobID int) ([][]byte, error) {
+	rows, err := db.Query(`SELECT png FROM job_images WHERE jobID = ? ORDER BY labelIndex`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var images [][]byte
+	for rows.Next() {
+		var png []byte
+		if err := rows.Scan(&png); err != nil {
+			println("Error scanning job image row:", err.Error())
+			continue
+		}
+		images = append(images, png)
+	}
+	return images, nil
+}
+
+func scanJob(rows *sql.Rows) (*Job, error) {
+	var j Job
+	var receivedAt, completedAt int64
+	err := rows.Scan(&j.JobID, &receivedAt, &completedAt, &j.SourceAddress, &j.Listener, &j.Mode, &j.ByteCount, &j.LabelCount, &j.Status, &j.Error)
+	if err != nil {
+		return nil, err
+	}
+	j.ReceivedAt = time.UnixMilli(receivedAt)
+	j.CompletedAt = time.UnixMilli(completedAt)
+	return &j, nil
+}
+
+// DeleteJob removes a job together with its images, outcomes and search text
+func DeleteJob(db *sql.DB, jobID int) error {
+	for _, table := range []string{"job_images", "job_outcomes", "job_text", "jobs"} {
+		_, err := db.Exec(`DELETE FROM `+table+` WHERE jobID = ?`, jobID)
+		if err != nil {
+			println("Error deleting job from", table+":", err.Error())
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Local printer states reported by ListLocalPrinters
+const (
+	LocalPrinterIdle     = "idle"
+	LocalPrinterPrinting = "printing"
+	LocalPrinterStopped  = "stopped"
+	LocalPrinterUnknown  = "unknown"
+)
+
+// LocalPrinter is a print queue installed on this machine
+type LocalPrinter struct {
+	Name         string   `json:"name"`
+	State        string   `json:"state"`
+	IsDefault    bool     `json:"isDefault"`
+	Media        []string `json:"media"`
+	DefaultMedia string   `json:"defaultMedia"`
+}
+
+// LocalPrintOptions control a job sent to a local printer
+type LocalPrintOptions struct {
+	Format string `json:"format"` // "png" or "pdf"
+	Copies int    `json:"copies"`
+	Media  string `json:"media"` // a media name from LocalPrinter.Media; empty for the queue default
+	Title  string `json:"title"`
+}
+
+// QueryInstalledPrinters returns the names of the printers installed on this machine
+func QueryInstalledPrinters() ([]string, error) {
+	printers, err := ListLocalPrinters()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, p := range printers {
+		names = append(names, p.Name)
+	}
+	return names, nil
+}
+
+// PrintPNGBytesToLocalPrinter prints a PNG byte array to a specified local printer
+func PrintPNGBytesToLocalPrinter(pngBytes []byte, printerName string) error {
+	return PrintToLocalPrinter(context.Background(), os.Stdout, pngBytes, printerName, LocalPrintOptions{Format: "png", Copies: 1})
+}
+
+func (o LocalPrintOptions) validate() error {
+	if o.Format != "png" && o.Format != "pdf" {
+		return fmt.Errorf("unsupported local print format %q, use png or pdf", o.Format)
+	}
+	if o.Copies < 0 {
+		return fmt.Errorf("invalid number of copies %d", o.Copies)
+	}
+	return nil
+}
+
+// GetLocalPrinters lists the printers installed on this machine with their media and state
+func (a *App) GetLocalPrinters() ([]LocalPrinter, error) {
+	return ListLocalPrinters()
+}
@@ -0,0 +1,174 @@
+//go:build !windows
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// runCUPS runs a CUPS command-line tool in the C locale so its output can be parsed
+func runCUPS(name string, args ...string) ([]byte, error) {
+	return runCUPSContext(context.Background(), name, args...)
+}
+
+// runCUPSContext is runCUPS, killing the command when ctx is done
+func runCUPSContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C", "LANG=C")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return output, fmt.Errorf("%s: %s", name, msg)
+		}
+		return output, fmt.Errorf("%s: %w", name, err)
+	}
+	return output, nil
+}
+
+// ListLocalPrinters lists the CUPS queues with their state, media and the default destination
+func ListLocalPrinters() ([]LocalPrinter, error) {
+	output, err := runCUPS("lpstat", "-p")
+	if err != nil {
+		// lpstat exits non-zero when no printers are configured
+		if strings.Contains(err.Error(), "No destinations added") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	printers := parseLpstatPrinters(output)
+
+	defaultName := ""
+	if out, err := runCUPS("lpstat", "-d"); err == nil {
+		defaultName = parseLpstatDefault(out)
+	}
+	for i := range printers {
+		printers[i].IsDefault = printers[i].Name == defaultName
+		if out, err := runCUPS("lpoptions", "-p", printers[i].Name, "-l"); err == nil {
+			printers[i].Media, printers[i].DefaultMedia = parseLpoptionsMedia(out)
+		}
+	}
+	return printers, nil
+}
+
+// parseLpstatPrinters reads lines such as "printer Zebra is idle.  enabled since ..."
+func parseLpstatPrinters(output []byte) []LocalPrinter {
+	var printers []LocalPrinter
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] != "printer" {
+			continue
+		}
+		p := LocalPrinter{Name: fields[1], State: LocalPrinterUnknown}
+		rest := strings.Join(fields[2:], " ")
+		switch {
+		case strings.HasPrefix(rest, "disabled"):
+			p.State = LocalPrinterStopped
+		case strings.HasPrefix(rest, "is idle"):
+			p.State = LocalPrinterIdle
+		case strings.HasPrefix(rest, "now printing"):
+			p.State = LocalPrinterPrinting
+		}
+		printers = append(printers, p)
+	}
+	return printers
+}
+
+// parseLpstatDefault reads "system default destination: NAME"
+func parseLpstatDefault(output []byte) string {
+	const prefix = "system default destination:"
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		}
+	}
+	return ""
+}
+
+// parseLpoptionsMedia reads the PageSize (or media) line of `lpoptions -l`, where the
+// default choice is marked with an asterisk, e.g. "PageSize/Media Size: *w288h432 Letter"
+func parseLpoptionsMedia(output []byte) ([]string, string) {
+	for _, line := range strings.Split(string(output), "\n") {
+		name, choices, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "/")
+		if name != "PageSize" && name != "media" {
+			continue
+		}
+		var media []string
+		defaultMedia := ""
+		for _, choice := range strings.Fields(choices) {
+			if strings.HasPrefix(choice, "*") {
+				choice = strings.TrimPrefix(choice, "*")
+				defaultMedia = choice
+			}
+			media = append(media, choice)
+		}
+		return media, defaultMedia
+	}
+	return nil, ""
+}
+
+// PrintToLocalPrinter submits a PNG or PDF to a CUPS queue with lp, giving up when ctx is done.
+// The lp output is written to out.
+func PrintToLocalPrinter(ctx context.Context, out io.Writer, data []byte, printerName string, opts LocalPrintOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp("", "temp-*."+opts.Format)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpFilePath := tmpFile.Name()
+	defer os.Remove(tmpFilePath)
+
+	_, err = tmpFile.Write(data)
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write %s bytes to temp file: %w", strings.ToUpper(opts.Format), err)
+	}
+	tmpFile.Close()
+
+	args := []string{"-d", printerName}
+	if opts.Copies > 1 {
+		args = append(args, "-n", strconv.Itoa(opts.Copies))
+	}
+	if opts.Media != "" {
+		args = append(args, "-o", "media="+opts.Media)
+	}
+	if opts.Format == "png" {
+		// Scale the label image to the selected media instead of printing it at screen resolution
+		args = append(args, "-o", "fit-to-page")
+	}
+	if opts.Title != "" {
+		args = append(args, "-t", opts.Title)
+	}
+	args = append(args, tmpFilePath)
+
+	output, err := runCUPSContext(ctx, "lp", args...)
+	if err != nil {
+		return fmt.Errorf("failed to print to %s: %w", printerName, err)
+	}
+	fmt.Fprintln(out, strings.TrimSpace(string(output)))
+	return nil
+}
+
+// Labels are printed to CUPS queues as PDFs sized to the label
+const localPrintFormat = "pdf"
+
+// localMediaForLabel names a custom CUPS media size in inches, e.g. Custom.4x6in
+func localMediaForLabel(widthInches, heightInches float64) string {
+	return fmt.Sprintf("Custom.%gx%gin", widthInches, heightInches)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// ListLocalPrinters uses PowerShell to list the installed printers and their state
+func ListLocalPrinters() ([]LocalPrinter, error) {
+	cmd := exec.Command("powershell", "-Command",
+		"Get-CimInstance Win32_Printer | Select-Object Name,Default,PrinterStatus,WorkOffline | ConvertTo-Json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Name          string
+		Default       bool
+		PrinterStatus int
+		WorkOffline   bool
+	}
+	// ConvertTo-Json writes a single object rather than an array when there is one printer
+	if err := json.Unmarshal(output, &rows); err != nil {
+		var row struct {
+			Name          string
+			Default       bool
+			PrinterStatus int
+			WorkOffline   bool
+		}
+		if err := json.Unmarshal(output, &row); err != nil {
+			return nil, fmt.Errorf("failed to read printer list: %w", err)
+		}
+		rows = append(rows, row)
+	}
+
+	var printers []LocalPrinter
+	for _, r := range rows {
+		p := LocalPrinter{Name: r.Name, IsDefault: r.Default, State: LocalPrinterUnknown}
+		// Win32_Printer PrinterStatus: 3 idle, 4 printing, 7 offline
+		switch {
+		case r.WorkOffline || r.PrinterStatus == 7:
+			p.State = LocalPrinterStopped
+		case r.PrinterStatus == 3:
+			p.State = LocalPrinterIdle
+		case r.PrinterStatus == 4:
+			p.State = LocalPrinterPrinting
+		}
+		printers = append(printers, p)
+	}
+	return printers, nil
+}
+
+// PrintToLocalPrinter prints a PNG to a local printer using Windows built-in tools (no external dependencies).
+// Windows has no built-in PDF printing command, and media selection is left to the driver defaults.
+func PrintToLocalPrinter(ctx context.Context, out io.Writer, data []byte, printerName string, opts LocalPrintOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if opts.Format != "png" {
+		return fmt.Errorf("printing %s files to local printers is not supported on Windows", opts.Format)
+	}
+	tmpFile, err := os.CreateTemp("", "temp-*.png")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpFilePath := tmpFile.Name()
+	defer os.Remove(tmpFilePath)
+
+	_, err = tmpFile.Write(data)
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write PNG bytes to temp file: %w", err)
+	}
+	tmpFile.Close()
+
+	copies := opts.Copies
+	if copies == 0 {
+		copies = 1
+	}
+	for i := 0; i < copies; i++ {
+		cmd := exec.CommandContext(ctx, "mspaint.exe", "/pt", tmpFilePath, printerName)
+		err = cmd.Run()
+		if err != nil {
+			fmt.Fprintf(out, "Attempted to print PNG using mspaint. Error (if any): %v\n", err)
+			if exitError, ok := err.(*exec.ExitError); ok {
+				fmt.Fprintf(out, "mspaint stderr: %s\n", string(exitError.Stderr))
+			}
+			return err
+		}
+	}
+	fmt.Fprintln(out, "PNG sent to printer via mspaint.")
+	return nil
+}
+
+// Labels are printed to Windows queues as PNG through mspaint
+const localPrintFormat = "png"
+
+// localMediaForLabel returns no media on Windows; the driver's paper size is used
+func localMediaForLabel(widthInches, heightInches float64) string {
+	return ""
+}
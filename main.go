@@ -22,23 +22,21 @@ var assets embed.FS
 var icon []byte
 
 func main() {
+	if code, handled := runCLI(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
 	printers, err := QueryInstalledPrinters()
 	if err != nil {
-		fmt.Errorf("Error querying installed printers: %v", err)
+		fmt.Println("Error querying installed printers:", err)
 	}
 	for _, v := range printers {
 		fmt.Println(v)
 
 	}
-	configPath, err := getMyAppConfigPath()
-	if err != nil {
-		log.Fatalf("Error getting application config path: %v", err)
-	}
-
-	dbFilePath := filepath.Join(configPath, "printEmulator.db")
-	db, err := ConnectSQLLite3(dbFilePath)
+	db, err := openAppDatabase()
 	if err != nil {
-		log.Fatalf("Cannot connect to database: %v", err)
+		log.Fatal(err)
 	}
 	defer db.SQL.Close()
 	// Create an instance of the app structure
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// mDNS / DNS-SD constants for advertising the emulator on the local network
+const (
+	mdnsAddress          = "224.0.0.251:5353"
+	mdnsTTL              = 120
+	mdnsCacheFlush       = 0x8000
+	mdnsUnicastResponse  = 0x8000
+	mdnsServiceEnum      = "_services._dns-sd._udp.local."
+	mdnsPDLService       = "_pdl-datastream._tcp.local."
+	mdnsIPPService       = "_ipp._tcp.local."
+	mdnsInstanceBaseName = "ZPL Printer Emulator"
+)
+
+// mdnsService is a single DNS-SD service registration (one SRV/TXT pair)
+type mdnsService struct {
+	serviceType string
+	port        int
+	txt         []string
+}
+
+// MDNSResponder answers multicast DNS queries for the emulated printer
+type MDNSResponder struct {
+	conn     *net.UDPConn
+	quit     chan any
+	wg       sync.WaitGroup
+	instance string
+	hostName string
+	ips      []net.IP
+	services []mdnsService
+}
+
+// NewMDNSResponder starts advertising the emulated printer via mDNS using the current settings.
+// ippPort is the port of a local IPP endpoint; pass 0 when none is running.
+func (a *App) NewMDNSResponder(ippPort int) (*MDNSResponder, error) {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "printer-emulator"
+	}
+	host = strings.Split(host, ".")[0]
+
+	// A loopback listener cannot be reached from the network, so there is nothing to advertise
+	if bindIP := net.ParseIP(CONN_HOST); (bindIP != nil && bindIP.IsLoopback()) || strings.EqualFold(CONN_HOST, "localhost") {
+		return nil, fmt.Errorf("the printer is bound to %s, which other machines cannot reach; bind to a LAN address or 0.0.0.0 to advertise it", CONN_HOST)
+	}
+
+	r := &MDNSResponder{
+		quit:     make(chan any),
+		instance: fmt.Sprintf("%s (%s)", mdnsInstanceBaseName, host),
+		hostName: host + ".local.",
+		ips:      mdnsAdvertisedIPs(),
+	}
+	if len(r.ips) == 0 {
+		return nil, fmt.Errorf("no IPv4 address available to advertise")
+	}
+
+	txt := a.mdnsTXTRecords()
+	r.services = append(r.services, mdnsService{
+		serviceType: mdnsPDLService,
+		port:        int(a.Settings.PrinterPort),
+		txt:         txt,
+	})
+	if ippPort > 0 {
+		ippTXT := append([]string{"rp=ipp/print"}, txt...)
+		r.services = append(r.services, mdnsService{
+			serviceType: mdnsIPPService,
+			port:        ippPort,
+			txt:         ippTXT,
+		})
+	}
+
+	groupAddr, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp4", nil, groupAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join mDNS multicast group: %w", err)
+	}
+	r.conn = conn
+
+	r.wg.Add(1)
+	go r.serve(groupAddr)
+
+	// Unsolicited announcements so browsers pick up the printer without querying
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for i := 0; i < 2; i++ {
+			r.announce(groupAddr, mdnsTTL)
+			select {
+			case <-r.quit:
+				return
+			case <-time.After(1 * time.Second):
+			}
+		}
+	}()
+
+	fmt.Printf("Advertising %q via mDNS\n", r.instance)
+	return r, nil
+}
+
+// mdnsTXTRecords describes the emulated printer model, resolution and media
+func (a *App) mdnsTXTRecords() []string {
+	dpi := int(float64(a.Settings.PrinterDPI.Dpi)*25.4 + 0.5)
+	return []string{
+		"txtvers=1",
+		"qtotal=1",
+		"ty=" + mdnsInstanceBaseName,
+		"product=(" + mdnsInstanceBaseName + ")",
+		"usb_MFG=Zebra Technologies",
+		"usb_MDL=ZPL Emulator",
+		"pdl=application/vnd.zebra-zpl",
+		fmt.Sprintf("dpi=%d", dpi),
+		fmt.Sprintf("media=%gx%gin", a.Settings.PrintWidth, a.Settings.PrintHeight),
+		"note=" + AppVersion,
+	}
+}
+
+// mdnsAdvertisedIPs returns the addresses clients should use to reach the listener. Loopback
+// addresses are never returned: every client resolving one would connect to itself.
+func mdnsAdvertisedIPs() []net.IP {
+	bindIP := net.ParseIP(CONN_HOST)
+	if bindIP != nil && !bindIP.IsUnspecified() {
+		if bindIP.IsLoopback() || bindIP.To4() == nil {
+			return nil
+		}
+		return []net.IP{bindIP.To4()}
+	}
+	var ips []net.IP
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		ips = append(ips, ipNet.IP.To4())
+	}
+	return ips
+}
+
+// Stop sends goodbye packets and shuts the responder down
+func (r *MDNSResponder) Stop() {
+	close(r.quit)
+	groupAddr, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err == nil {
+		r.announce(groupAddr, 0)
+	}
+	r.conn.Close()
+	waitTimeout(&r.wg, 1*time.Second)
+}
+
+func (r *MDNSResponder) serve(groupAddr *net.UDPAddr) {
+	defer r.wg.Done()
+
+	buf := make([]byte, 9000)
+	for {
+		n, src, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-r.quit:
+				return
+			default:
+				log.Println("mDNS read error", err)
+				continue
+			}
+		}
+		r.handleQuery(buf[:n], src, groupAddr)
+	}
+}
+
+// handleQuery answers any questions in the packet that refer to our records
+func (r *MDNSResponder) handleQuery(packet []byte, src *net.UDPAddr, groupAddr *net.UDPAddr) {
+	var p dnsmessage.Parser
+	header, err := p.Start(packet)
+	if err != nil || header.Response {
+		return
+	}
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return
+	}
+
+	var answers, extras []dnsmessage.Resource
+	unicast := false
+	for _, q := range questions {
+		if uint16(q.Class)&mdnsUnicastResponse != 0 {
+			unicast = true
+		}
+		ans, ext := r.answer(q)
+		answers = append(answers, ans...)
+		extras = append(extras, ext...)
+	}
+	if len(answers) == 0 {
+		return
+	}
+
+	// Legacy unicast queries (source port other than 5353) get a direct reply
+	// echoing the query ID and questions, as required by RFC 6762 section 6.7
+	legacy := src.Port != 5353
+	respHeader := dnsmessage.Header{Response: true, Authoritative: true}
+	var echo []dnsmessage.Question
+	if legacy {
+		respHeader.ID = header.ID
+		echo = questions
+	}
+	msg, err := buildMDNSMessage(respHeader, echo, answers, extras)
+	if err != nil {
+		log.Println("mDNS build error", err)
+		return
+	}
+	dest := groupAddr
+	if legacy || unicast {
+		dest = src
+	}
+	r.conn.WriteToUDP(msg, dest)
+}
+
+// answer returns the answer and additional records for a single question
+func (r *MDNSResponder) answer(q dnsmessage.Question) ([]dnsmessage.Resource, []dnsmessage.Resource) {
+	name := strings.ToLower(q.Name.String())
+	wants := func(t dnsmessage.Type) bool {
+		return q.Type == t || q.Type == dnsmessage.TypeALL
+	}
+
+	var answers, extras []dnsmessage.Resource
+	if name == mdnsServiceEnum && wants(dnsmessage.TypePTR) {
+		for _, svc := range r.services {
+			answers = append(answers, r.ptrRecord(mdnsServiceEnum, svc.serviceType, mdnsTTL))
+		}
+		return answers, nil
+	}
+	if name == strings.ToLower(r.hostName) && wants(dnsmessage.TypeA) {
+		return r.addressRecords(mdnsTTL), nil
+	}
+	for _, svc := range r.services {
+		instanceName := r.instanceName(svc)
+		switch name {
+		case svc.serviceType:
+			if wants(dnsmessage.TypePTR) {
+				answers = append(answers, r.ptrRecord(svc.serviceType, instanceName, mdnsTTL))
+				extras = append(extras, r.serviceRecords(svc, mdnsTTL)...)
+				extras = append(extras, r.addressRecords(mdnsTTL)...)
+			}
+		case strings.ToLower(instanceName):
+			if wants(dnsmessage.TypeSRV) || wants(dnsmessage.TypeTXT) {
+				answers = append(answers, r.serviceRecords(svc, mdnsTTL)...)
+				extras = append(extras, r.addressRecords(mdnsTTL)...)
+			}
+		}
+	}
+	return answers, extras
+}
+
+// announce multicasts every record; a ttl of 0 tells caches to forget us
+func (r *MDNSResponder) announce(groupAddr *net.UDPAddr, ttl uint32) {
+	var answers []dnsmessage.Resource
+	for _, svc := range r.services {
+		answers = append(answers, r.ptrRecord(svc.serviceType, r.instanceName(svc), ttl))
+		answers = append(answers, r.serviceRecords(svc, ttl)...)
+	}
+	answers = append(answers, r.addressRecords(ttl)...)
+
+	msg, err := buildMDNSMessage(dnsmessage.Header{Response: true, Authoritative: true}, nil, answers, nil)
+	if err != nil {
+		log.Println("mDNS build error", err)
+		return
+	}
+	r.conn.WriteToUDP(msg, groupAddr)
+}
+
+func (r *MDNSResponder) instanceName(svc mdnsService) string {
+	return r.instance + "." + svc.serviceType
+}
+
+func (r *MDNSResponder) ptrRecord(name string, target string, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mdnsName(name), Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.PTRResource{PTR: mdnsName(target)},
+	}
+}
+
+func (r *MDNSResponder) serviceRecords(svc mdnsService, ttl uint32) []dnsmessage.Resource {
+	name := mdnsName(r.instanceName(svc))
+	class := dnsmessage.Class(uint16(dnsmessage.ClassINET) | mdnsCacheFlush)
+	return []dnsmessage.Resource{
+		{
+			Header: dnsmessage.ResourceHeader{Name: name, Class: class, TTL: ttl},
+			Body:   &dnsmessage.SRVResource{Port: uint16(svc.port), Target: mdnsName(r.hostName)},
+		},
+		{
+			Header: dnsmessage.ResourceHeader{Name: name, Class: class, TTL: ttl},
+			Body:   &dnsmessage.TXTResource{TXT: svc.txt},
+		},
+	}
+}
+
+func (r *MDNSResponder) addressRecords(ttl uint32) []dnsmessage.Resource {
+	class := dnsmessage.Class(uint16(dnsmessage.ClassINET) | mdnsCacheFlush)
+	var records []dnsmessage.Resource
+	for _, ip := range r.ips {
+		var a [4]byte
+		copy(a[:], ip.To4())
+		records = append(records, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: mdnsName(r.hostName), Class: class, TTL: ttl},
+			Body:   &dnsmessage.AResource{A: a},
+		})
+	}
+	return records
+}
+
+// mdnsName converts a fully qualified name to a dnsmessage.Name
+func mdnsName(name string) dnsmessage.Name {
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		// Names are built from our own constants; fall back to a truncated name rather than panic
+		n, _ = dnsmessage.NewName(mdnsInstanceBaseName + ".local.")
+	}
+	return n
+}
+
+func buildMDNSMessage(header dnsmessage.Header, questions []dnsmessage.Question, answers []dnsmessage.Resource, extras []dnsmessage.Resource) ([]byte, error) {
+	msg := dnsmessage.Message{
+		Header:      header,
+		Questions:   questions,
+		Answers:     answers,
+		Additionals: extras,
+	}
+	return msg.Pack()
+}
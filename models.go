@@ -3,35 +3,72 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 )
 
 type Settings struct {
-	SettingID       int        `json:"settingID"`
-	PrintWidth      float64    `json:"printWidth"`
-	PrintHeight     float64    `json:"printHeight"`
-	PrintRotation   float64    `json:"printRotation"`
-	PrinterPort     float64    `json:"printerPort"`
-	PrintPath       string     `json:"printerPath"`
-	PrinterDPI      PrinterDPI `json:"printerDPI"`
-	DefaultPrinter  int        `json:"defaultPrinter"`
-	AutoStartServer bool       `json:"autoStartServer"`
+	SettingID       int             `json:"settingID"`
+	PrintWidth      float64         `json:"printWidth"`
+	PrintHeight     float64         `json:"printHeight"`
+	PrintRotation   float64         `json:"printRotation"`
+	PrinterPort     float64         `json:"printerPort"`
+	PrintPath       string          `json:"printerPath"`
+	PrinterDPI      PrinterDPI      `json:"printerDPI"`
+	DefaultPrinter  int             `json:"defaultPrinter"`
+	AutoStartServer bool            `json:"autoStartServer"`
+	AdvertiseMDNS   bool            `json:"advertiseMDNS"`
+	SNMPEnabled     bool            `json:"snmpEnabled"`
+	SNMPPort        int             `json:"snmpPort"`
+	SNMPCommunity   string          `json:"snmpCommunity"`
+	SNMPBind        string          `json:"snmpBind"` // Address the SNMP agent listens on; empty for all interfaces
+	Retention       RetentionPolicy `json:"retention"`
+	GoldenCompare   CompareOptions  `json:"goldenCompare"` // Tolerance for golden image checks
+	HotFolderPath   string          `json:"hotFolderPath"`
+	FTPEnabled      bool            `json:"ftpEnabled"`
+	FTPPort         int             `json:"ftpPort"`
+	FTPUser         string          `json:"ftpUser"`       // Empty allows anonymous login
+	FTPPassword     string          `json:"-"`             // Only checked when FTPUser is set; never exported
+	RelayParallel   int             `json:"relayParallel"` // Printers a relay group sends to at once
+	RelayTimeout    int             `json:"relayTimeout"`  // Seconds allowed per relay destination
+}
+
+// RetentionPolicy limits how much saved label output and job history is kept.
+// A zero value means no limit.
+type RetentionPolicy struct {
+	MaxAgeDays int `json:"maxAgeDays"`
+	MaxCount   int `json:"maxCount"`
+	MaxTotalMB int `json:"maxTotalMB"`
 }
 
 type Printer struct {
-	PrinterID   int    `json:"printerID"`
-	PrinterName string `json:"printerName"`
-	IPAddress   string `json:"ipAddress"`
-	PrinterPort int    `json:"printerPort"`
-	PrinterType string `json:"printerType"`
-	IPPEndpoint string `json:"ippEndpoint"` // IPP endpoint path (e.g., /ipp/print)
-	UseTLS      bool   `json:"useTLS"`      // Use IPPS (TLS) instead of IPP
+	PrinterID    int    `json:"printerID"`
+	PrinterName  string `json:"printerName"`
+	IPAddress    string `json:"ipAddress"`
+	PrinterPort  int    `json:"printerPort"`
+	PrinterType  string `json:"printerType"`
+	IPPEndpoint  string `json:"ippEndpoint"`  // IPP endpoint path (e.g., /ipp/print)
+	UseTLS       bool   `json:"useTLS"`       // Use IPPS (TLS) instead of IPP
+	QueueName    string `json:"queueName"`    // Installed OS print queue for Local printers
+	OutputDir    string `json:"outputDir"`    // Directory File printers write to
+	FileTemplate string `json:"fileTemplate"` // File name template, e.g. {printer}-{date}-{time}-{label}
+	FileFormats  string `json:"fileFormats"`  // Comma separated list of zpl, png and pdf
 }
 
-// RelayGroup represents a group of printer IDs
-// e.g. [1,2,3]
+// RelayGroup is a named set of printers that jobs are relayed to
 type RelayGroup struct {
-	GroupID    int   `json:"groupID"`
-	PrinterIDs []int `json:"printerIDs"`
+	GroupID     int                `json:"groupID"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Strategy    string             `json:"strategy"`   // broadcast, failover, round-robin or least-busy; see relay.go
+	Members     []RelayGroupMember `json:"members"`    // In the order they are tried
+	PrinterIDs  []int              `json:"printerIDs"` // Enabled members in order, e.g. [1,2,3]
+}
+
+// RelayGroupMember is one printer in a relay group
+type RelayGroupMember struct {
+	PrinterID   int    `json:"printerID"`
+	PrinterName string `json:"printerName"` // Filled in when the group is loaded
+	Enabled     bool   `json:"enabled"`
 }
 
 // SettingsDB provides methods to interact with the settings table
@@ -41,10 +78,25 @@ func (s *Settings) SaveToDB(db *sql.DB) error {
 	if s.AutoStartServer {
 		autoStartInt = 1
 	}
+	advertiseInt := 0
+	if s.AdvertiseMDNS {
+		advertiseInt = 1
+	}
+	snmpInt := 0
+	if s.SNMPEnabled {
+		snmpInt = 1
+	}
+	ftpInt := 0
+	if s.FTPEnabled {
+		ftpInt = 1
+	}
 	_, err := db.Exec(`
 		INSERT INTO settings (
-			settingID, printWidth, printHeight, printRotation, printerPort, printPath, printerDPI_value, printerDPI_desc, defaultPrinter, autoStartServer
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			settingID, printWidth, printHeight, printRotation, printerPort, printPath, printerDPI_value, printerDPI_desc, defaultPrinter, autoStartServer, advertiseMDNS, snmpEnabled, snmpPort, snmpCommunity,
+			retentionMaxAgeDays, retentionMaxCount, retentionMaxTotalMB, hotFolderPath,
+			ftpEnabled, ftpPort, ftpUser, ftpPassword, relayParallel, relayTimeout, snmpBind,
+			goldenColorTolerance, goldenMaxDiffPixels
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(settingID) DO UPDATE SET
 			printWidth=excluded.printWidth,
 			printHeight=excluded.printHeight,
@@ -54,7 +106,24 @@ func (s *Settings) SaveToDB(db *sql.DB) error {
 			printerDPI_value=excluded.printerDPI_value,
 			printerDPI_desc=excluded.printerDPI_desc,
 			defaultPrinter=excluded.defaultPrinter,
-			autoStartServer=excluded.autoStartServer
+			autoStartServer=excluded.autoStartServer,
+			advertiseMDNS=excluded.advertiseMDNS,
+			snmpEnabled=excluded.snmpEnabled,
+			snmpPort=excluded.snmpPort,
+			snmpCommunity=excluded.snmpCommunity,
+			retentionMaxAgeDays=excluded.retentionMaxAgeDays,
+			retentionMaxCount=excluded.retentionMaxCount,
+			retentionMaxTotalMB=excluded.retentionMaxTotalMB,
+			hotFolderPath=excluded.hotFolderPath,
+			ftpEnabled=excluded.ftpEnabled,
+			ftpPort=excluded.ftpPort,
+			ftpUser=excluded.ftpUser,
+			ftpPassword=excluded.ftpPassword,
+			relayParallel=excluded.relayParallel,
+			relayTimeout=excluded.relayTimeout,
+			snmpBind=excluded.snmpBind,
+			goldenColorTolerance=excluded.goldenColorTolerance,
+			goldenMaxDiffPixels=excluded.goldenMaxDiffPixels
 	`,
 		s.SettingID,
 		s.PrintWidth,
@@ -66,6 +135,23 @@ func (s *Settings) SaveToDB(db *sql.DB) error {
 		s.PrinterDPI.Description,
 		s.DefaultPrinter,
 		autoStartInt,
+		advertiseInt,
+		snmpInt,
+		s.SNMPPort,
+		s.SNMPCommunity,
+		s.Retention.MaxAgeDays,
+		s.Retention.MaxCount,
+		s.Retention.MaxTotalMB,
+		s.HotFolderPath,
+		ftpInt,
+		s.FTPPort,
+		s.FTPUser,
+		s.FTPPassword,
+		s.RelayParallel,
+		s.RelayTimeout,
+		s.SNMPBind,
+		s.GoldenCompare.ColorTolerance,
+		s.GoldenCompare.MaxDiffPixels,
 	)
 	if err != nil {
 		println("Error saving settings to DB:", err.Error())
@@ -74,18 +160,31 @@ func (s *Settings) SaveToDB(db *sql.DB) error {
 }
 
 func LoadSettingsFromDB(db *sql.DB) (*Settings, error) {
-	row := db.QueryRow(`SELECT settingID, printWidth, printHeight, printRotation, printerPort, printPath, printerDPI_value, printerDPI_desc, defaultPrinter, COALESCE(autoStartServer, 0) FROM settings LIMIT 1`)
+	row := db.QueryRow(`SELECT settingID, printWidth, printHeight, printRotation, printerPort, printPath, printerDPI_value, printerDPI_desc, defaultPrinter, COALESCE(autoStartServer, 0), COALESCE(advertiseMDNS, 0), COALESCE(snmpEnabled, 0), COALESCE(snmpPort, 1161), COALESCE(snmpCommunity, 'public'),
+		COALESCE(retentionMaxAgeDays, 0), COALESCE(retentionMaxCount, 0), COALESCE(retentionMaxTotalMB, 0), COALESCE(hotFolderPath, ''),
+		COALESCE(ftpEnabled, 0), COALESCE(ftpPort, 21), COALESCE(ftpUser, ''), COALESCE(ftpPassword, ''),
+		COALESCE(relayParallel, 4), COALESCE(relayTimeout, 30), COALESCE(snmpBind, ''),
+		COALESCE(goldenColorTolerance, 0), COALESCE(goldenMaxDiffPixels, 0) FROM settings LIMIT 1`)
 	var s Settings
 	var dpiValue int
 	var dpiDesc string
 	var autoStartInt int
-	err := row.Scan(&s.SettingID, &s.PrintWidth, &s.PrintHeight, &s.PrintRotation, &s.PrinterPort, &s.PrintPath, &dpiValue, &dpiDesc, &s.DefaultPrinter, &autoStartInt)
+	var advertiseInt int
+	var snmpInt int
+	var ftpInt int
+	err := row.Scan(&s.SettingID, &s.PrintWidth, &s.PrintHeight, &s.PrintRotation, &s.PrinterPort, &s.PrintPath, &dpiValue, &dpiDesc, &s.DefaultPrinter, &autoStartInt, &advertiseInt, &snmpInt, &s.SNMPPort, &s.SNMPCommunity, &s.Retention.MaxAgeDays, &s.Retention.MaxCount, &s.Retention.MaxTotalMB, &s.HotFolderPath,
+		&ftpInt, &s.FTPPort, &s.FTPUser, &s.FTPPassword,
+		&s.RelayParallel, &s.RelayTimeout, &s.SNMPBind,
+		&s.GoldenCompare.ColorTolerance, &s.GoldenCompare.MaxDiffPixels)
 	if err != nil {
 		println("Error loading settings from DB:", err.Error())
 		return nil, err
 	}
 	s.PrinterDPI = PrinterDPI{Dpi: dpiValue, Description: dpiDesc}
 	s.AutoStartServer = autoStartInt != 0
+	s.AdvertiseMDNS = advertiseInt != 0
+	s.SNMPEnabled = snmpInt != 0
+	s.FTPEnabled = ftpInt != 0
 	return &s, nil
 }
 
@@ -101,7 +200,24 @@ func InitSettingsTable(db *sql.DB) error {
 			printerDPI_value INTEGER,
 			printerDPI_desc TEXT,
 			defaultPrinter INTEGER,
-			autoStartServer INTEGER DEFAULT 0
+			autoStartServer INTEGER DEFAULT 0,
+			advertiseMDNS INTEGER DEFAULT 0,
+			snmpEnabled INTEGER DEFAULT 0,
+			snmpPort INTEGER DEFAULT 1161,
+			snmpCommunity TEXT DEFAULT 'public',
+			retentionMaxAgeDays INTEGER DEFAULT 0,
+			retentionMaxCount INTEGER DEFAULT 0,
+			retentionMaxTotalMB INTEGER DEFAULT 0,
+			hotFolderPath TEXT DEFAULT '',
+			ftpEnabled INTEGER DEFAULT 0,
+			ftpPort INTEGER DEFAULT 21,
+			ftpUser TEXT DEFAULT '',
+			ftpPassword TEXT DEFAULT '',
+			relayParallel INTEGER DEFAULT 4,
+			relayTimeout INTEGER DEFAULT 30,
+			snmpBind TEXT DEFAULT '',
+			goldenColorTolerance INTEGER DEFAULT 0,
+			goldenMaxDiffPixels INTEGER DEFAULT 0
 		)
 	`)
 	if err != nil {
@@ -111,6 +227,23 @@ func InitSettingsTable(db *sql.DB) error {
 
 	// Add new column if it doesn't exist (for migrations)
 	db.Exec(`ALTER TABLE settings ADD COLUMN autoStartServer INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN advertiseMDNS INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN snmpEnabled INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN snmpPort INTEGER DEFAULT 1161`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN snmpCommunity TEXT DEFAULT 'public'`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN retentionMaxAgeDays INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN retentionMaxCount INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN retentionMaxTotalMB INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN hotFolderPath TEXT DEFAULT ''`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN ftpEnabled INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN ftpPort INTEGER DEFAULT 21`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN ftpUser TEXT DEFAULT ''`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN ftpPassword TEXT DEFAULT ''`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN relayParallel INTEGER DEFAULT 4`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN relayTimeout INTEGER DEFAULT 30`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN snmpBind TEXT DEFAULT ''`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN goldenColorTolerance INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE settings ADD COLUMN goldenMaxDiffPixels INTEGER DEFAULT 0`)
 
 	return nil
 }
@@ -125,7 +258,11 @@ func InitPrintersTable(db *sql.DB) error {
 			printerPort INTEGER NOT NULL,
 			printerType TEXT NOT NULL,
 			ippEndpoint TEXT DEFAULT '/ipp/print',
-			useTLS INTEGER DEFAULT 0
+			useTLS INTEGER DEFAULT 0,
+			queueName TEXT DEFAULT '',
+			outputDir TEXT DEFAULT '',
+			fileTemplate TEXT DEFAULT '',
+			fileFormats TEXT DEFAULT ''
 		)`)
 	if err != nil {
 		println("Error initializing printers table:", err.Error())
@@ -135,6 +272,10 @@ func InitPrintersTable(db *sql.DB) error {
 	// Add new columns if they don't exist (for migrations)
 	db.Exec(`ALTER TABLE printers ADD COLUMN ippEndpoint TEXT DEFAULT '/ipp/print'`)
 	db.Exec(`ALTER TABLE printers ADD COLUMN useTLS INTEGER DEFAULT 0`)
+	db.Exec(`ALTER TABLE printers ADD COLUMN queueName TEXT DEFAULT ''`)
+	db.Exec(`ALTER TABLE printers ADD COLUMN outputDir TEXT DEFAULT ''`)
+	db.Exec(`ALTER TABLE printers ADD COLUMN fileTemplate TEXT DEFAULT ''`)
+	db.Exec(`ALTER TABLE printers ADD COLUMN fileFormats TEXT DEFAULT ''`)
 
 	return nil
 }
@@ -150,9 +291,9 @@ func AddPrinter(db *sql.DB, p *Printer) error {
 	}
 
 	res, err := db.Exec(`
-		INSERT INTO printers (printerName, ipAddress, printerPort, printerType, ippEndpoint, useTLS)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, p.PrinterName, p.IPAddress, p.PrinterPort, p.PrinterType, p.IPPEndpoint, useTLSInt)
+		INSERT INTO printers (printerName, ipAddress, printerPort, printerType, ippEndpoint, useTLS, queueName, outputDir, fileTemplate, fileFormats)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, p.PrinterName, p.IPAddress, p.PrinterPort, p.PrinterType, p.IPPEndpoint, useTLSInt, p.QueueName, p.OutputDir, p.FileTemplate, p.FileFormats)
 	if err != nil {
 		println("Error adding printer:", err.Error())
 		return err
@@ -165,7 +306,7 @@ func AddPrinter(db *sql.DB, p *Printer) error {
 }
 
 func GetPrinters(db *sql.DB) ([]Printer, error) {
-	rows, err := db.Query(`SELECT printerID, printerName, ipAddress, printerPort, printerType, COALESCE(ippEndpoint, '/ipp/print'), COALESCE(useTLS, 0) FROM printers`)
+	rows, err := db.Query(`SELECT printerID, printerName, ipAddress, printerPort, printerType, COALESCE(ippEndpoint, '/ipp/print'), COALESCE(useTLS, 0), COALESCE(queueName, ''), COALESCE(outputDir, ''), COALESCE(fileTemplate, ''), COALESCE(fileFormats, '') FROM printers`)
 	if err != nil {
 		println("Error getting printers:", err.Error())
 		return nil, err
@@ -175,7 +316,7 @@ func GetPrinters(db *sql.DB) ([]Printer, error) {
 	for rows.Next() {
 		var p Printer
 		var useTLSInt int
-		err := rows.Scan(&p.PrinterID, &p.PrinterName, &p.IPAddress, &p.PrinterPort, &p.PrinterType, &p.IPPEndpoint, &useTLSInt)
+		err := rows.Scan(&p.PrinterID, &p.PrinterName, &p.IPAddress, &p.PrinterPort, &p.PrinterType, &p.IPPEndpoint, &useTLSInt, &p.QueueName, &p.OutputDir, &p.FileTemplate, &p.FileFormats)
 		if err != nil {
 			println("Error scanning printer row:", err.Error())
 			continue
@@ -195,8 +336,8 @@ func UpdatePrinter(db *sql.DB, p *Printer) error {
 		p.IPPEndpoint = "/ipp/print"
 	}
 	_, err := db.Exec(`
-		UPDATE printers SET printerName=?, ipAddress=?, printerPort=?, printerType=?, ippEndpoint=?, useTLS=? WHERE printerID=?
-	`, p.PrinterName, p.IPAddress, p.PrinterPort, p.PrinterType, p.IPPEndpoint, useTLSInt, p.PrinterID)
+		UPDATE printers SET printerName=?, ipAddress=?, printerPort=?, printerType=?, ippEndpoint=?, useTLS=?, queueName=?, outputDir=?, fileTemplate=?, fileFormats=? WHERE printerID=?
+	`, p.PrinterName, p.IPAddress, p.PrinterPort, p.PrinterType, p.IPPEndpoint, useTLSInt, p.QueueName, p.OutputDir, p.FileTemplate, p.FileFormats, p.PrinterID)
 	if err != nil {
 		println("Error updating printer:", err.Error())
 	}
@@ -211,60 +352,331 @@ func DeletePrinter(db *sql.DB, printerID int) error {
 	return err
 }
 
-// Initialize relay_groups table
+// Initialize relay_groups and relay_group_members tables. Databases from before member
+// rows existed keep each group's printers as a JSON printerIDs column; those are moved
+// into relay_group_members once, dropping printers that no longer exist.
 func InitRelayGroupsTable(db *sql.DB) error {
 	_, err := db.Exec(`
 		CREATE TABLE IF NOT EXISTS relay_groups (
 			groupID INTEGER PRIMARY KEY AUTOINCREMENT,
-			printerIDs TEXT NOT NULL -- JSON array of printer IDs
+			name TEXT DEFAULT '',
+			description TEXT DEFAULT '',
+			strategy TEXT DEFAULT 'broadcast'
 		)`)
 	if err != nil {
 		println("Error initializing relay_groups table:", err.Error())
+		return err
 	}
-	return err
+	db.Exec(`ALTER TABLE relay_groups ADD COLUMN strategy TEXT DEFAULT 'broadcast'`)
+
+	var legacy int
+	db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('relay_groups') WHERE name = 'printerIDs'`).Scan(&legacy)
+	if legacy > 0 {
+		if err := migrateRelayGroups(db); err != nil {
+			println("Error migrating relay groups:", err.Error())
+			return err
+		}
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS relay_group_members (
+			groupID INTEGER NOT NULL REFERENCES relay_groups(groupID) ON DELETE CASCADE,
+			printerID INTEGER NOT NULL REFERENCES printers(printerID) ON DELETE CASCADE,
+			position INTEGER NOT NULL DEFAULT 0,
+			enabled INTEGER DEFAULT 1,
+			PRIMARY KEY (groupID, printerID)
+		)`)
+	if err != nil {
+		println("Error initializing relay_group_members table:", err.Error())
+		return err
+	}
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_relay_group_members_printerID ON relay_group_members(printerID)`)
+	return nil
+}
+
+// migrateRelayGroups rebuilds relay_groups without the printerIDs column and writes its
+// contents as member rows
+func migrateRelayGroups(db *sql.DB) error {
+	type legacyGroup struct {
+		id         int
+		strategy   string
+		printerIDs []int
+	}
+	rows, err := db.Query(`SELECT groupID, printerIDs, COALESCE(strategy, 'broadcast') FROM relay_groups`)
+	if err != nil {
+		return err
+	}
+	var groups []legacyGroup
+	for rows.Next() {
+		var g legacyGroup
+		var idsJSON string
+		if err := rows.Scan(&g.id, &idsJSON, &g.strategy); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := json.Unmarshal([]byte(idsJSON), &g.printerIDs); err != nil {
+			println("Error unmarshaling printerIDs:", err.Error())
+		}
+		groups = append(groups, g)
+	}
+	rows.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		`DROP TABLE IF EXISTS relay_group_members`,
+		`DROP TABLE relay_groups`,
+		`CREATE TABLE relay_groups (
+			groupID INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT DEFAULT '',
+			description TEXT DEFAULT '',
+			strategy TEXT DEFAULT 'broadcast'
+		)`,
+		`CREATE TABLE relay_group_members (
+			groupID INTEGER NOT NULL REFERENCES relay_groups(groupID) ON DELETE CASCADE,
+			printerID INTEGER NOT NULL REFERENCES printers(printerID) ON DELETE CASCADE,
+			position INTEGER NOT NULL DEFAULT 0,
+			enabled INTEGER DEFAULT 1,
+			PRIMARY KEY (groupID, printerID)
+		)`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	for _, g := range groups {
+		// Old groups had no names; leave them empty so a merge import still matches them by members
+		_, err := tx.Exec(`INSERT INTO relay_groups (groupID, name, strategy) VALUES (?, '', ?)`, g.id, g.strategy)
+		if err != nil {
+			return err
+		}
+		seen := map[int]bool{}
+		position := 0
+		for _, printerID := range g.printerIDs {
+			if seen[printerID] {
+				continue
+			}
+			seen[printerID] = true
+			res, err := tx.Exec(`
+				INSERT INTO relay_group_members (groupID, printerID, position, enabled)
+				SELECT ?, printerID, ?, 1 FROM printers WHERE printerID = ?
+			`, g.id, position, printerID)
+			if err != nil {
+				return err
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				position++
+			}
+		}
+	}
+	return tx.Commit()
 }
 
-// Add a relay group
-func AddRelayGroup(db *sql.DB, printerIDs []int) error {
-	idsJSON, err := json.Marshal(printerIDs)
+// memberList returns the group's members, or its printer IDs as enabled members when it
+// was built from IDs alone
+func (g *RelayGroup) memberList() []RelayGroupMember {
+	if len(g.Members) > 0 {
+		return g.Members
+	}
+	members := make([]RelayGroupMember, 0, len(g.PrinterIDs))
+	for _, id := range g.PrinterIDs {
+		members = append(members, RelayGroupMember{PrinterID: id, Enabled: true})
+	}
+	return members
+}
+
+// validate checks a relay group before it is saved
+func (g *RelayGroup) validate() error {
+	if err := validateRelayStrategy(g.Strategy); err != nil {
+		return err
+	}
+	members := g.memberList()
+	if len(members) == 0 {
+		return fmt.Errorf("a relay group needs at least one printer")
+	}
+	seen := map[int]bool{}
+	for _, m := range members {
+		if seen[m.PrinterID] {
+			return fmt.Errorf("printer %d is in the group more than once", m.PrinterID)
+		}
+		seen[m.PrinterID] = true
+	}
+	return nil
+}
+
+// insertRelayGroup adds a group and its members inside tx and returns the new group ID
+func insertRelayGroup(tx *sql.Tx, g RelayGroup) (int, error) {
+	if g.Strategy == "" {
+		g.Strategy = RelayBroadcast
+	}
+	res, err := tx.Exec(`INSERT INTO relay_groups (name, description, strategy) VALUES (?, ?, ?)`, g.Name, g.Description, g.Strategy)
 	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), replaceRelayGroupMembers(tx, int(id), g.memberList())
+}
+
+// replaceRelayGroupMembers sets a group's members, in order, inside tx
+func replaceRelayGroupMembers(tx *sql.Tx, groupID int, members []RelayGroupMember) error {
+	if _, err := tx.Exec(`DELETE FROM relay_group_members WHERE groupID=?`, groupID); err != nil {
 		return err
 	}
-	_, err = db.Exec(`INSERT INTO relay_groups (printerIDs) VALUES (?)`, string(idsJSON))
+	for i, m := range members {
+		enabledInt := 0
+		if m.Enabled {
+			enabledInt = 1
+		}
+		_, err := tx.Exec(`INSERT INTO relay_group_members (groupID, printerID, position, enabled) VALUES (?, ?, ?, ?)`, groupID, m.PrinterID, i, enabledInt)
+		if err != nil {
+			return fmt.Errorf("error adding printer %d to relay group: %w", m.PrinterID, err)
+		}
+	}
+	return nil
+}
+
+// Add a relay group with its members and return its ID
+func AddRelayGroup(db *sql.DB, g RelayGroup) (int, error) {
+	if err := g.validate(); err != nil {
+		return 0, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	id, err := insertRelayGroup(tx, g)
 	if err != nil {
 		println("Error adding relay group:", err.Error())
+		return 0, err
 	}
-	return err
+	return id, tx.Commit()
 }
 
-// Get all relay groups
+// UpdateRelayGroup saves a group's name, description, strategy and members
+func UpdateRelayGroup(db *sql.DB, g RelayGroup) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+	if g.Strategy == "" {
+		g.Strategy = RelayBroadcast
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`UPDATE relay_groups SET name=?, description=?, strategy=? WHERE groupID=?`, g.Name, g.Description, g.Strategy, g.GroupID)
+	if err != nil {
+		println("Error updating relay group:", err.Error())
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("relay group %d not found", g.GroupID)
+	}
+	if err := replaceRelayGroupMembers(tx, g.GroupID, g.memberList()); err != nil {
+		println("Error updating relay group:", err.Error())
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get all relay groups with their members in order
 func GetRelayGroups(db *sql.DB) ([]RelayGroup, error) {
-	rows, err := db.Query(`SELECT groupID, printerIDs FROM relay_groups`)
+	return queryRelayGroups(db, "")
+}
+
+// GetRelayGroupByID looks up a relay group by its groupID
+func GetRelayGroupByID(db *sql.DB, groupID int) (*RelayGroup, error) {
+	groups, err := queryRelayGroups(db, `WHERE groupID = ?`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, nil // Not found
+	}
+	return &groups[0], nil
+}
+
+// queryRelayGroups loads the groups matching where, filling in members and the enabled
+// printer IDs
+func queryRelayGroups(db *sql.DB, where string, args ...any) ([]RelayGroup, error) {
+	rows, err := db.Query(`SELECT groupID, COALESCE(name, ''), COALESCE(description, ''), COALESCE(strategy, 'broadcast') FROM relay_groups `+where+` ORDER BY groupID`, args...)
 	if err != nil {
 		println("Error getting relay groups:", err.Error())
 		return nil, err
 	}
-	defer rows.Close()
 	var groups []RelayGroup
+	index := map[int]int{}
 	for rows.Next() {
 		var g RelayGroup
-		var idsJSON string
-		err := rows.Scan(&g.GroupID, &idsJSON)
-		if err != nil {
+		if err := rows.Scan(&g.GroupID, &g.Name, &g.Description, &g.Strategy); err != nil {
 			println("Error scanning relay group row:", err.Error())
 			continue
 		}
-		err = json.Unmarshal([]byte(idsJSON), &g.PrinterIDs)
-		if err != nil {
-			println("Error unmarshaling printerIDs:", err.Error())
+		g.Members = []RelayGroupMember{}
+		g.PrinterIDs = []int{}
+		index[g.GroupID] = len(groups)
+		groups = append(groups, g)
+	}
+	rows.Close()
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	rows, err = db.Query(`
+		SELECT m.groupID, m.printerID, COALESCE(p.printerName, ''), COALESCE(m.enabled, 1)
+		FROM relay_group_members m JOIN printers p ON p.printerID = m.printerID
+		ORDER BY m.groupID, m.position
+	`)
+	if err != nil {
+		println("Error getting relay group members:", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var groupID, enabledInt int
+		var m RelayGroupMember
+		if err := rows.Scan(&groupID, &m.PrinterID, &m.PrinterName, &enabledInt); err != nil {
+			println("Error scanning relay group member row:", err.Error())
 			continue
 		}
-		groups = append(groups, g)
+		i, ok := index[groupID]
+		if !ok {
+			continue
+		}
+		m.Enabled = enabledInt != 0
+		groups[i].Members = append(groups[i].Members, m)
+		if m.Enabled {
+			groups[i].PrinterIDs = append(groups[i].PrinterIDs, m.PrinterID)
+		}
 	}
 	return groups, nil
 }
 
-// Delete a relay group by groupID
+// SetRelayGroupStrategy changes how a relay group delivers jobs
+func SetRelayGroupStrategy(db *sql.DB, groupID int, strategy string) error {
+	if err := validateRelayStrategy(strategy); err != nil {
+		return err
+	}
+	res, err := db.Exec(`UPDATE relay_groups SET strategy=? WHERE groupID=?`, strategy, groupID)
+	if err != nil {
+		println("Error updating relay group:", err.Error())
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("relay group %d not found", groupID)
+	}
+	return nil
+}
+
+// Delete a relay group by groupID; its members go with it
 func DeleteRelayGroup(db *sql.DB, groupID int) error {
 	_, err := db.Exec(`DELETE FROM relay_groups WHERE groupID=?`, groupID)
 	if err != nil {
@@ -275,10 +687,10 @@ func DeleteRelayGroup(db *sql.DB, groupID int) error {
 
 // GetPrinterByID looks up a printer by its printerID
 func GetPrinterByID(db *sql.DB, printerID int) (*Printer, error) {
-	row := db.QueryRow(`SELECT printerID, printerName, ipAddress, printerPort, printerType, COALESCE(ippEndpoint, '/ipp/print'), COALESCE(useTLS, 0) FROM printers WHERE printerID = ?`, printerID)
+	row := db.QueryRow(`SELECT printerID, printerName, ipAddress, printerPort, printerType, COALESCE(ippEndpoint, '/ipp/print'), COALESCE(useTLS, 0), COALESCE(queueName, ''), COALESCE(outputDir, ''), COALESCE(fileTemplate, ''), COALESCE(fileFormats, '') FROM printers WHERE printerID = ?`, printerID)
 	var p Printer
 	var useTLSInt int
-	err := row.Scan(&p.PrinterID, &p.PrinterName, &p.IPAddress, &p.PrinterPort, &p.PrinterType, &p.IPPEndpoint, &useTLSInt)
+	err := row.Scan(&p.PrinterID, &p.PrinterName, &p.IPAddress, &p.PrinterPort, &p.PrinterType, &p.IPPEndpoint, &useTLSInt, &p.QueueName, &p.OutputDir, &p.FileTemplate, &p.FileFormats)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
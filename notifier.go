@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Notifier receives the status events and errors meant for the user. The GUI forwards
+// them to the Wails frontend; headless mode writes them to the log.
+type Notifier interface {
+	Emit(event string, data ...any)
+	Error(title string, message string)
+}
+
+// wailsNotifier sends events to the frontend through the Wails runtime
+type wailsNotifier struct {
+	app *App
+}
+
+func (n *wailsNotifier) Emit(event string, data ...any) {
+	runtime.EventsEmit(n.ctx(), event, data...)
+}
+
+func (n *wailsNotifier) Error(title string, message string) {
+	var dialog runtime.MessageDialogOptions
+	dialog.Title = title
+	dialog.Message = message
+	dialog.Type = runtime.ErrorDialog
+	runtime.MessageDialog(n.ctx(), dialog)
+}
+
+// ctx is read on every call because the Wails context is only set once startup runs
+func (n *wailsNotifier) ctx() context.Context {
+	return n.app.ctx
+}
+
+// logNotifier writes events to a logger for headless mode
+type logNotifier struct {
+	logger *log.Logger
+}
+
+func newLogNotifier(w io.Writer) *logNotifier {
+	return &logNotifier{logger: log.New(w, "", log.LstdFlags)}
+}
+
+func (n *logNotifier) Emit(event string, data ...any) {
+	switch event {
+	case "Unblock":
+		// Only the frontend's buttons care about this one
+	case "NewPrint":
+		n.logger.Println("Label rendered")
+	default:
+		n.logger.Println(event, summarizeEventData(data))
+	}
+}
+
+func (n *logNotifier) Error(title string, message string) {
+	n.logger.Printf("%s: %s", title, message)
+}
+
+// summarizeEventData keeps large payloads such as images out of the log
+func summarizeEventData(data []any) string {
+	s := ""
+	for _, d := range data {
+		switch v := d.(type) {
+		case CompareResult:
+			s += fmt.Sprintf("%s label %d: %s (%d pixels differ) ", v.Name, v.LabelIndex, v.Status, v.DiffPixels)
+		case *ConfigReport:
+			s += fmt.Sprintf("from %s %s ", v.Path, v.Error)
+		case *ResendResult:
+			s += fmt.Sprintf("job %d sent to %d printer(s) ", v.JobID, len(v.Outcomes))
+		case *RelayResult:
+			failed := 0
+			for _, o := range v.Outcomes {
+				if o.failed() {
+					failed++
+				}
+			}
+			s += fmt.Sprintf("group %d (%s): printed by %v, %d of %d printer(s) failed in %dms ", v.GroupID, v.Strategy, v.PrintedBy, failed, len(v.Outcomes), v.ElapsedMs)
+		default:
+			s += fmt.Sprint(v) + " "
+		}
+	}
+	return s
+}
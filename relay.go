@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Defaults for relay fan-out when the settings leave them at zero
+const (
+	defaultRelayParallel = 4
+	defaultRelayTimeout  = 30 // seconds
+)
+
+// Relay group strategies. Broadcast sends every job to all printers; failover sends it to
+// the first printer in the list that accepts it. Round-robin and least-busy spread jobs
+// across the printers, falling through to the next one if the chosen printer fails.
+const (
+	RelayBroadcast  = "broadcast"
+	RelayFailover   = "failover"
+	RelayRoundRobin = "round-robin"
+	RelayLeastBusy  = "least-busy"
+)
+
+// relayBalancer is the state load-balanced relay groups keep between jobs
+type relayBalancer struct {
+	mu       sync.Mutex
+	next     map[int]int // round-robin position per group
+	inflight map[int]int // sends in progress per printer
+}
+
+var RelayBalancer = &relayBalancer{next: map[int]int{}, inflight: map[int]int{}}
+
+// RelayResult is the outcome of sending one job to a relay group, emitted as "RelayResult"
+type RelayResult struct {
+	GroupID   int          `json:"groupID"`
+	Strategy  string       `json:"strategy"`
+	Outcomes  []JobOutcome `json:"outcomes"`
+	PrintedBy []int        `json:"printedBy"` // IDs of the printers that took the job
+	ElapsedMs int64        `json:"elapsedMs"`
+}
+
+func validateRelayStrategy(strategy string) error {
+	switch strategy {
+	case "", RelayBroadcast, RelayFailover, RelayRoundRobin, RelayLeastBusy:
+		return nil
+	}
+	return fmt.Errorf("unknown relay strategy %q, use %s, %s, %s or %s", strategy, RelayBroadcast, RelayFailover, RelayRoundRobin, RelayLeastBusy)
+}
+
+// order returns the group's printers in the order to try them: the strategy's pick first,
+// then the rest of the group after it
+func (b *relayBalancer) order(group RelayGroup) []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ids := group.PrinterIDs
+	if len(ids) == 0 {
+		return nil
+	}
+
+	first := 0
+	switch group.Strategy {
+	case RelayRoundRobin:
+		first = b.next[group.GroupID] % len(ids)
+		b.next[group.GroupID] = (first + 1) % len(ids)
+	case RelayLeastBusy:
+		for i, id := range ids {
+			if b.inflight[id] < b.inflight[ids[first]] {
+				first = i
+			}
+		}
+	}
+
+	order := make([]int, 0, len(ids))
+	for i := range ids {
+		order = append(order, ids[(first+i)%len(ids)])
+	}
+	return order
+}
+
+func (b *relayBalancer) started(printerID int) {
+	b.mu.Lock()
+	b.inflight[printerID]++
+	b.mu.Unlock()
+}
+
+func (b *relayBalancer) finished(printerID int) {
+	b.mu.Lock()
+	if b.inflight[printerID] > 0 {
+		b.inflight[printerID]--
+	}
+	b.mu.Unlock()
+}
+
+// relayLimits returns how many printers are sent to at once and how long each may take
+func (s *Settings) relayLimits() (int, time.Duration) {
+	parallel := s.RelayParallel
+	if parallel <= 0 {
+		parallel = defaultRelayParallel
+	}
+	timeout := s.RelayTimeout
+	if timeout <= 0 {
+		timeout = defaultRelayTimeout
+	}
+	return parallel, time.Duration(timeout) * time.Second
+}
+
+// ProcessRelayGroup sends zpl to the selected relay group and returns the result per printer
+func (a *App) ProcessRelayGroup(zpl string) ([]JobOutcome, error) {
+	_, _, group := printSelection()
+	return a.relayToGroup(group, zpl)
+}
+
+// relayToGroup delivers zpl using the group's strategy and reports each printer tried.
+// A group with no enabled printers is an error rather than a job that printed nowhere.
+func (a *App) relayToGroup(group RelayGroup, zpl string) ([]JobOutcome, error) {
+	if len(group.PrinterIDs) == 0 {
+		return nil, fmt.Errorf("relay group %d has no enabled printers", group.GroupID)
+	}
+	start := time.Now()
+	result := &RelayResult{GroupID: group.GroupID, Strategy: group.Strategy}
+	switch group.Strategy {
+	case RelayFailover:
+		result.Outcomes = a.relayInOrder(group.PrinterIDs, zpl)
+	case RelayRoundRobin, RelayLeastBusy:
+		result.Outcomes = a.relayBalanced(group, zpl)
+	default:
+		result.Strategy = RelayBroadcast
+		result.Outcomes = a.relayBroadcast(group, zpl)
+	}
+	for _, o := range result.Outcomes {
+		if o.Success {
+			result.PrintedBy = append(result.PrintedBy, o.PrinterID)
+		}
+	}
+	result.ElapsedMs = time.Since(start).Milliseconds()
+	a.ui.Emit("RelayResult", result)
+	return result.Outcomes, nil
+}
+
+// relayBroadcast sends zpl to every printer in the group at once, up to the configured
+// parallelism
+func (a *App) relayBroadcast(group RelayGroup, zpl string) []JobOutcome {
+	parallel, timeout := a.Settings.relayLimits()
+	outcomes := make([]JobOutcome, len(group.PrinterIDs))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, printerID := range group.PrinterIDs {
+		printer, err := a.relayPrinter(printerID)
+		if err != nil {
+			outcomes[i] = newJobOutcome(Printer{PrinterID: printerID}, err)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, p Printer) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			outcomes[i] = a.sendWithTimeout(p, zpl, timeout)
+		}(i, *printer)
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// relayBalanced sends the whole job to one printer picked by the balancer. A job is never
+// split, so every label received on a connection prints on the same printer.
+func (a *App) relayBalanced(group RelayGroup, zpl string) []JobOutcome {
+	return a.relayInOrder(RelayBalancer.order(group), zpl)
+}
+
+// relayInOrder tries the printers in order and stops at the first one that accepts the job.
+// Printers that failed before it are marked as failed over.
+func (a *App) relayInOrder(printerIDs []int, zpl string) []JobOutcome {
+	_, timeout := a.Settings.relayLimits()
+	var outcomes []JobOutcome
+	for _, printerID := range printerIDs {
+		var o JobOutcome
+		printer, err := a.relayPrinter(printerID)
+		if err != nil {
+			o = newJobOutcome(Printer{PrinterID: printerID}, err)
+		} else {
+			o = a.sendWithTimeout(*printer, zpl, timeout)
+		}
+		if o.Success {
+			for i := range outcomes {
+				outcomes[i].FailedOver = true
+			}
+			return append(outcomes, o)
+		}
+		outcomes = append(outcomes, o)
+	}
+	// Nobody printed it; every attempt stays a failure
+	return outcomes
+}
+
+// relayPrinter looks up a relay group member, which may have been deleted since the group was made
+func (a *App) relayPrinter(printerID int) (*Printer, error) {
+	printer, err := GetPrinterByID(a.db, printerID)
+	if err != nil {
+		fmt.Fprintln(a.logOutput(), "Error getting printer by ID:", err)
+		return nil, err
+	}
+	if printer == nil {
+		return nil, fmt.Errorf("printer %d not found", printerID)
+	}
+	return printer, nil
+}
+
+// sendWithTimeout sends to one printer and times how long it took. The send is given the
+// timeout as its deadline, so the connection or print command is abandoned when it passes.
+func (a *App) sendWithTimeout(p Printer, zpl string, timeout time.Duration) JobOutcome {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	RelayBalancer.started(p.PrinterID)
+	err := a.sendToPrinter(ctx, p, zpl)
+	RelayBalancer.finished(p.PrinterID)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("no response from %s within %s", p.PrinterName, timeout)
+	}
+	if err != nil {
+		log.Printf("Error relaying to %s: %v", p.PrinterName, err)
+	}
+	o := newJobOutcome(p, err)
+	o.LatencyMs = time.Since(start).Milliseconds()
+	return o
+}
+
+// SetRelayLimits sets how many relay printers are sent to at once and the seconds allowed
+// for each; zero uses the defaults
+func (a *App) SetRelayLimits(parallel int, timeoutSeconds int) error {
+	if parallel < 0 || timeoutSeconds < 0 {
+		return fmt.Errorf("parallelism and timeout must not be negative")
+	}
+	return a.updateSettings(func(s *Settings) {
+		s.RelayParallel = parallel
+		s.RelayTimeout = timeoutSeconds
+	})
+}
+
+// GetRelayParallel returns how many relay printers are sent to at once
+func (a *App) GetRelayParallel() int {
+	parallel, _ := a.Settings.relayLimits()
+	return parallel
+}
+
+// GetRelayTimeout returns the seconds allowed for each relay printer
+func (a *App) GetRelayTimeout() int {
+	_, timeout := a.Settings.relayLimits()
+	return int(timeout / time.Second)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// ResendResult is reported to the frontend after a job is resent
+type ResendResult struct {
+	JobID    int          `json:"jobID"`
+	Outcomes []JobOutcome `json:"outcomes"`
+}
+
+// jobRawData returns a job's raw bytes from the spool, falling back to the job history
+func (a *App) jobRawData(jobID int) ([]byte, error) {
+	if a.spool != nil {
+		data, err := a.spool.Load(jobID)
+		if err == nil {
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	job, err := GetJobByID(a.db, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || len(job.RawData) == 0 {
+		return nil, fmt.Errorf("job %d is no longer available", jobID)
+	}
+	return job.RawData, nil
+}
+
+// ReprintJob renders a stored job again in the emulator preview and returns the label images
+func (a *App) ReprintJob(jobID int) ([][]byte, error) {
+	data, err := a.jobRawData(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return a.emulateLabels(string(data))
+}
+
+// ResendJobToPrinter sends a stored job to a saved printer
+func (a *App) ResendJobToPrinter(jobID int, printerID int) (*ResendResult, error) {
+	data, err := a.jobRawData(jobID)
+	if err != nil {
+		return nil, err
+	}
+	printer, err := GetPrinterByID(a.db, printerID)
+	if err != nil {
+		return nil, err
+	}
+	if printer == nil {
+		return nil, fmt.Errorf("printer %d not found", printerID)
+	}
+	err = a.SendToPrinter(*printer, string(data))
+	result := &ResendResult{JobID: jobID, Outcomes: []JobOutcome{newJobOutcome(*printer, err)}}
+	a.ui.Emit("JobResent", result)
+	return result, nil
+}
+
+// ResendJobToRelayGroup sends a stored job to every printer in a relay group
+func (a *App) ResendJobToRelayGroup(jobID int, groupID int) (*ResendResult, error) {
+	data, err := a.jobRawData(jobID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := GetRelayGroupByID(a.db, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, fmt.Errorf("relay group %d not found", groupID)
+	}
+	outcomes, err := a.relayToGroup(*group, string(data))
+	if err != nil {
+		return nil, err
+	}
+	result := &ResendResult{JobID: jobID, Outcomes: outcomes}
+	a.ui.Emit("JobResent", result)
+	return result, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// How often the janitor enforces the retention policy
+const retentionInterval = 1 * time.Hour
+
+// savedLabelPattern matches the PNGs written by emulateLabels; nothing else in PrintPath is touched
+const savedLabelPattern = "label-print-*.png"
+
+// RetentionReport lists what a retention pass removes (or would remove in a dry run)
+type RetentionReport struct {
+	DryRun       bool     `json:"dryRun"`
+	Files        []string `json:"files"`
+	FileBytes    int64    `json:"fileBytes"`
+	JobIDs       []int    `json:"jobIDs"`
+	JobBytes     int64    `json:"jobBytes"`
+	RanAt        string   `json:"ranAt"`
+	ErrorMessage string   `json:"errorMessage"`
+}
+
+// retentionItem is one file or job considered by the policy
+type retentionItem struct {
+	file     string
+	jobID    int
+	size     int64
+	received time.Time
+}
+
+// Janitor enforces the retention policy in the background
+type Janitor struct {
+	quit chan any
+	done chan any
+}
+
+// expired applies the policy to items and returns those to remove.
+// Items are kept newest first until any limit is exceeded.
+func (p RetentionPolicy) expired(items []retentionItem, now time.Time) []retentionItem {
+	sort.Slice(items, func(i, j int) bool { return items[i].received.After(items[j].received) })
+
+	var cutoff time.Time
+	if p.MaxAgeDays > 0 {
+		cutoff = now.AddDate(0, 0, -p.MaxAgeDays)
+	}
+	maxBytes := int64(p.MaxTotalMB) * 1024 * 1024
+
+	var remove []retentionItem
+	var total int64
+	kept := 0
+	for _, item := range items {
+		switch {
+		case !cutoff.IsZero() && item.received.Before(cutoff):
+			remove = append(remove, item)
+		case p.MaxCount > 0 && kept >= p.MaxCount:
+			remove = append(remove, item)
+		case maxBytes > 0 && total+item.size > maxBytes:
+			remove = append(remove, item)
+		default:
+			kept++
+			total += item.size
+		}
+	}
+	return remove
+}
+
+// savedLabelFiles lists the label PNGs in the print directory
+func savedLabelFiles(dir string) ([]retentionItem, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, savedLabelPattern))
+	if err != nil {
+		return nil, err
+	}
+	var items []retentionItem
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		items = append(items, retentionItem{file: m, size: info.Size(), received: info.ModTime()})
+	}
+	return items, nil
+}
+
+// storedJobs lists every job in the history with its stored size (raw data plus images)
+func storedJobs(db *sql.DB) ([]retentionItem, error) {
+	rows, err := db.Query(`
+		SELECT jobID, receivedAt,
+			COALESCE(length(rawData), 0) + COALESCE((SELECT SUM(length(png)) FROM job_images WHERE job_images.jobID = jobs.jobID), 0)
+		FROM jobs
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []retentionItem
+	for rows.Next() {
+		var item retentionItem
+		var receivedAt int64
+		if err := rows.Scan(&item.jobID, &receivedAt, &item.size); err != nil {
+			println("Error scanning job size row:", err.Error())
+			continue
+		}
+		item.received = time.UnixMilli(receivedAt)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// applyRetention works out what the policy removes and deletes it unless dryRun is set
+func (a *App) applyRetention(dryRun bool) (*RetentionReport, error) {
+	policy := a.Settings.Retention
+	now := time.Now()
+	report := &RetentionReport{DryRun: dryRun, RanAt: now.Format(time.RFC3339)}
+	if policy == (RetentionPolicy{}) {
+		return report, nil
+	}
+
+	files, err := savedLabelFiles(a.Settings.PrintPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range policy.expired(files, now) {
+		if !dryRun {
+			if err := os.Remove(item.file); err != nil && !os.IsNotExist(err) {
+				report.ErrorMessage = err.Error()
+				continue
+			}
+		}
+		report.Files = append(report.Files, item.file)
+		report.FileBytes += item.size
+	}
+
+	jobs, err := storedJobs(a.db)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range policy.expired(jobs, now) {
+		if !dryRun {
+			if err := DeleteJob(a.db, item.jobID); err != nil {
+				report.ErrorMessage = err.Error()
+				continue
+			}
+			if a.spool != nil {
+				a.spool.Remove(item.jobID)
+			}
+		}
+		report.JobIDs = append(report.JobIDs, item.jobID)
+		report.JobBytes += item.size
+	}
+	return report, nil
+}
+
+// startJanitor runs the retention policy now and then every retentionInterval
+func (a *App) startJanitor() {
+	if a.janitor != nil {
+		return
+	}
+	j := &Janitor{quit: make(chan any), done: make(chan any)}
+	a.janitor = j
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(retentionInterval)
+		defer ticker.Stop()
+		for {
+			report, err := a.applyRetention(false)
+			if err != nil {
+				fmt.Println("Error applying retention policy:", err)
+			} else if len(report.Files) > 0 || len(report.JobIDs) > 0 {
+				fmt.Printf("Retention removed %d files and %d jobs\n", len(report.Files), len(report.JobIDs))
+			}
+			select {
+			case <-j.quit:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopJanitor stops the background retention goroutine
+func (a *App) stopJanitor() {
+	if a.janitor == nil {
+		return
+	}
+	close(a.janitor.quit)
+	<-a.janitor.done
+	a.janitor = nil
+}
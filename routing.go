@@ -0,0 +1,564 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Where a routing rule sends a matching job
+const (
+	RouteToPrinter  = "printer"
+	RouteToGroup    = "group"
+	RouteToEmulator = "emulate"
+	RouteDrop       = "drop"
+	RouteFollowMode = "mode" // Use the print mode's destination
+)
+
+// How far a job's ^PW/^LL size may be from a rule's label size, in inches
+const routeSizeTolerance = 0.1
+
+// RoutingRule sends jobs that match every condition it sets to a destination. Rules are
+// tried in position order. The default rule is always last, has no conditions and can't be
+// deleted; it follows the print mode unless its action is changed.
+type RoutingRule struct {
+	RuleID       int    `json:"ruleID"`
+	Position     int    `json:"position"`
+	Name         string `json:"name"`
+	Enabled      bool   `json:"enabled"`
+	IsDefault    bool   `json:"isDefault"`
+	SourceCIDR   string `json:"sourceCIDR"`   // Client IP or CIDR, e.g. 10.0.1.0/24
+	ListenerPort int    `json:"listenerPort"` // Port the job arrived on; 0 matches any
+	ZPLPattern   string `json:"zplPattern"`   // Regular expression over the raw ZPL
+	Comment      string `json:"comment"`      // Text in a ^FX comment, ignoring case
+	LabelSize    string `json:"labelSize"`    // WIDTHxHEIGHT in inches such as 2.25x1.25, from ^PW and ^LL
+	Action       string `json:"action"`       // printer, group, emulate, drop or mode
+	TargetID     int    `json:"targetID"`     // Printer or relay group ID
+
+	// Parsed conditions, set by compile
+	network     *net.IPNet
+	pattern     *regexp.Regexp
+	labelWidth  float64
+	labelHeight float64
+}
+
+// RuleCheck says whether one rule matched a job and why
+type RuleCheck struct {
+	RuleID  int    `json:"ruleID"`
+	Name    string `json:"name"`
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason"`
+}
+
+// RouteDecision is where a job goes and how that was decided
+type RouteDecision struct {
+	RuleID   int         `json:"ruleID"` // 0 when no rule matched
+	RuleName string      `json:"ruleName"`
+	Action   string      `json:"action"`
+	TargetID int         `json:"targetID"`
+	Checks   []RuleCheck `json:"checks"`
+	Summary  string      `json:"summary"`
+
+	origin string // What chose the destination, for errors; empty when the print mode did
+}
+
+// routingRuleCache keeps the compiled rules between jobs; saving any rule clears it
+type routingRuleCache struct {
+	mu    sync.Mutex
+	rules []RoutingRule
+}
+
+var loadedRoutingRules = &routingRuleCache{}
+
+// get returns the rules, loading and compiling them if they changed since the last job
+func (c *routingRuleCache) get(db *sql.DB) ([]RoutingRule, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rules != nil {
+		return c.rules, nil
+	}
+	rules, err := GetRoutingRules(db)
+	if err != nil {
+		return nil, err
+	}
+	c.rules = rules
+	return rules, nil
+}
+
+func (c *routingRuleCache) reset() {
+	c.mu.Lock()
+	c.rules = nil
+	c.mu.Unlock()
+}
+
+// routeInput is what rules are matched against
+type routeInput struct {
+	sourceIP     net.IP
+	listenerPort int
+	zpl          string
+}
+
+// Initialize routing_rules table
+func InitRoutingRulesTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS routing_rules (
+			ruleID INTEGER PRIMARY KEY AUTOINCREMENT,
+			position INTEGER NOT NULL DEFAULT 0,
+			name TEXT,
+			enabled INTEGER DEFAULT 1,
+			sourceCIDR TEXT DEFAULT '',
+			listenerPort INTEGER DEFAULT 0,
+			zplPattern TEXT DEFAULT '',
+			comment TEXT DEFAULT '',
+			labelSize TEXT DEFAULT '',
+			action TEXT NOT NULL,
+			targetID INTEGER DEFAULT 0
+		)`)
+	if err != nil {
+		println("Error initializing routing_rules table:", err.Error())
+		return err
+	}
+	db.Exec(`ALTER TABLE routing_rules ADD COLUMN isDefault INTEGER DEFAULT 0`)
+	_, err = db.Exec(`
+		INSERT INTO routing_rules (position, name, enabled, action, isDefault)
+		SELECT 0, 'Default', 1, ?, 1 WHERE NOT EXISTS (SELECT 1 FROM routing_rules WHERE isDefault = 1)
+	`, RouteFollowMode)
+	if err != nil {
+		println("Error adding default routing rule:", err.Error())
+	}
+	return err
+}
+
+// compile parses a rule's source, pattern and label size so matching jobs doesn't
+func (r *RoutingRule) compile() error {
+	r.network, r.pattern, r.labelWidth, r.labelHeight = nil, nil, 0, 0
+	if r.SourceCIDR != "" {
+		network, err := parseSourceCIDR(r.SourceCIDR)
+		if err != nil {
+			return err
+		}
+		r.network = network
+	}
+	if r.ZPLPattern != "" {
+		pattern, err := regexp.Compile(r.ZPLPattern)
+		if err != nil {
+			return fmt.Errorf("invalid ZPL pattern: %w", err)
+		}
+		r.pattern = pattern
+	}
+	if r.LabelSize != "" {
+		width, height, err := parseLabelSize(r.LabelSize)
+		if err != nil {
+			return err
+		}
+		r.labelWidth, r.labelHeight = width, height
+	}
+	return nil
+}
+
+// validate checks a rule's patterns and destination before it is saved
+func (r *RoutingRule) validate() error {
+	if err := r.compile(); err != nil {
+		return err
+	}
+	if r.ListenerPort < 0 || r.ListenerPort > 65535 {
+		return fmt.Errorf("port %d is out of range", r.ListenerPort)
+	}
+	if r.IsDefault && (r.SourceCIDR != "" || r.ListenerPort != 0 || r.ZPLPattern != "" || r.Comment != "" || r.LabelSize != "") {
+		return fmt.Errorf("the default rule matches every job and can't have conditions")
+	}
+	switch r.Action {
+	case RouteToPrinter, RouteToGroup:
+		if r.TargetID <= 0 {
+			return fmt.Errorf("rule %q needs a %s to send to", r.Name, r.Action)
+		}
+	case RouteToEmulator, RouteDrop, RouteFollowMode:
+	default:
+		return fmt.Errorf("unknown action %q, use %s, %s, %s, %s or %s", r.Action, RouteToPrinter, RouteToGroup, RouteToEmulator, RouteDrop, RouteFollowMode)
+	}
+	return nil
+}
+
+// AddRoutingRule adds a rule after the existing ones, before the default rule, and returns its ID
+func AddRoutingRule(db *sql.DB, r RoutingRule) (int, error) {
+	r.IsDefault = false
+	if err := r.validate(); err != nil {
+		return 0, err
+	}
+	enabledInt := 0
+	if r.Enabled {
+		enabledInt = 1
+	}
+	res, err := db.Exec(`
+		INSERT INTO routing_rules (position, name, enabled, sourceCIDR, listenerPort, zplPattern, comment, labelSize, action, targetID)
+		VALUES ((SELECT COALESCE(MAX(position), 0) + 1 FROM routing_rules WHERE isDefault = 0), ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.Name, enabledInt, r.SourceCIDR, r.ListenerPort, r.ZPLPattern, r.Comment, r.LabelSize, r.Action, r.TargetID)
+	if err != nil {
+		println("Error adding routing rule:", err.Error())
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// UpdateRoutingRule saves a rule's conditions and destination; its position is unchanged.
+// The default rule stays enabled and without conditions.
+func UpdateRoutingRule(db *sql.DB, r RoutingRule) error {
+	var defaultInt int
+	err := db.QueryRow(`SELECT COALESCE(isDefault, 0) FROM routing_rules WHERE ruleID=?`, r.RuleID).Scan(&defaultInt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("routing rule %d not found", r.RuleID)
+	}
+	if err != nil {
+		return err
+	}
+	r.IsDefault = defaultInt != 0
+	if r.IsDefault {
+		r.Enabled = true
+	}
+	if err := r.validate(); err != nil {
+		return err
+	}
+	enabledInt := 0
+	if r.Enabled {
+		enabledInt = 1
+	}
+	_, err = db.Exec(`
+		UPDATE routing_rules SET name=?, enabled=?, sourceCIDR=?, listenerPort=?, zplPattern=?, comment=?, labelSize=?, action=?, targetID=? WHERE ruleID=?
+	`, r.Name, enabledInt, r.SourceCIDR, r.ListenerPort, r.ZPLPattern, r.Comment, r.LabelSize, r.Action, r.TargetID, r.RuleID)
+	if err != nil {
+		println("Error updating routing rule:", err.Error())
+	}
+	return err
+}
+
+// GetRoutingRules returns all rules in the order they are tried, with their conditions compiled
+func GetRoutingRules(db *sql.DB) ([]RoutingRule, error) {
+	rows, err := db.Query(`
+		SELECT ruleID, position, COALESCE(name, ''), COALESCE(enabled, 1), COALESCE(isDefault, 0), COALESCE(sourceCIDR, ''), COALESCE(listenerPort, 0),
+			COALESCE(zplPattern, ''), COALESCE(comment, ''), COALESCE(labelSize, ''), action, COALESCE(targetID, 0)
+		FROM routing_rules ORDER BY isDefault, position, ruleID
+	`)
+	if err != nil {
+		println("Error getting routing rules:", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+	var rules []RoutingRule
+	for rows.Next() {
+		var r RoutingRule
+		var enabledInt, defaultInt int
+		err := rows.Scan(&r.RuleID, &r.Position, &r.Name, &enabledInt, &defaultInt, &r.SourceCIDR, &r.ListenerPort, &r.ZPLPattern, &r.Comment, &r.LabelSize, &r.Action, &r.TargetID)
+		if err != nil {
+			println("Error scanning routing rule row:", err.Error())
+			continue
+		}
+		r.Enabled = enabledInt != 0
+		r.IsDefault = defaultInt != 0
+		// A rule saved by an older version may not parse; it then never matches
+		if err := r.compile(); err != nil {
+			println("Error compiling routing rule:", err.Error())
+			r.Enabled = false
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// DeleteRoutingRule removes a rule; the default rule can't be removed
+func DeleteRoutingRule(db *sql.DB, ruleID int) error {
+	var defaultInt int
+	db.QueryRow(`SELECT COALESCE(isDefault, 0) FROM routing_rules WHERE ruleID=?`, ruleID).Scan(&defaultInt)
+	if defaultInt != 0 {
+		return fmt.Errorf("the default routing rule can't be deleted, change its action instead")
+	}
+	_, err := db.Exec(`DELETE FROM routing_rules WHERE ruleID=?`, ruleID)
+	if err != nil {
+		println("Error deleting routing rule:", err.Error())
+	}
+	return err
+}
+
+// ReorderRoutingRules sets the order rules are tried in; rules not listed keep their place after
+// them, and the default rule stays last
+func ReorderRoutingRules(db *sql.DB, ruleIDs []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE routing_rules SET position = position + ?`, len(ruleIDs)); err != nil {
+		println("Error reordering routing rules:", err.Error())
+		return err
+	}
+	for i, id := range ruleIDs {
+		if _, err := tx.Exec(`UPDATE routing_rules SET position=? WHERE ruleID=?`, i, id); err != nil {
+			println("Error reordering routing rules:", err.Error())
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// parseSourceCIDR accepts a CIDR or a single address
+func parseSourceCIDR(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid source address %q", s)
+		}
+		bits := 32
+		if ip.To4() == nil {
+			bits = 128
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source network %q", s)
+	}
+	return network, nil
+}
+
+// zplComments returns the text of every ^FX comment
+func zplComments(zpl string) []string {
+	var comments []string
+	for _, cmd := range ParseZPL(zpl) {
+		if cmd.Name == "FX" {
+			comments = append(comments, cmd.Params)
+		}
+	}
+	return comments
+}
+
+// zplLabelSize returns the first ^PW width and ^LL length in inches, or zero when not set
+func zplLabelSize(zpl string, dpmm int) (float64, float64) {
+	if dpmm <= 0 {
+		dpmm = 8
+	}
+	width, length := 0, 0
+	for _, cmd := range ParseZPL(zpl) {
+		switch {
+		case cmd.Name == "PW" && width == 0:
+			width = zplParamInt(zplParams(cmd.Params), 0, 0)
+		case cmd.Name == "LL" && length == 0:
+			length = zplParamInt(zplParams(cmd.Params), 0, 0)
+		}
+	}
+	dotsPerInch := float64(dpmm) * 25.4
+	return float64(width) / dotsPerInch, float64(length) / dotsPerInch
+}
+
+// match reports whether the job meets every condition the rule sets, and why
+func (r *RoutingRule) match(in routeInput, dpmm int) (bool, string) {
+	if !r.Enabled {
+		return false, "rule is disabled"
+	}
+	var met []string
+	if r.network != nil {
+		if in.sourceIP == nil {
+			return false, "job has no source IP address"
+		}
+		if !r.network.Contains(in.sourceIP) {
+			return false, fmt.Sprintf("source %s is not in %s", in.sourceIP, r.SourceCIDR)
+		}
+		met = append(met, "source in "+r.SourceCIDR)
+	}
+	if r.ListenerPort != 0 {
+		if in.listenerPort != r.ListenerPort {
+			return false, fmt.Sprintf("arrived on port %d, not %d", in.listenerPort, r.ListenerPort)
+		}
+		met = append(met, fmt.Sprintf("port %d", r.ListenerPort))
+	}
+	if r.pattern != nil {
+		if !r.pattern.MatchString(in.zpl) {
+			return false, fmt.Sprintf("ZPL does not match /%s/", r.ZPLPattern)
+		}
+		met = append(met, fmt.Sprintf("ZPL matches /%s/", r.ZPLPattern))
+	}
+	if r.Comment != "" {
+		found := false
+		for _, c := range zplComments(in.zpl) {
+			if strings.Contains(strings.ToLower(c), strings.ToLower(r.Comment)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, fmt.Sprintf("no ^FX comment contains %q", r.Comment)
+		}
+		met = append(met, fmt.Sprintf("^FX comment contains %q", r.Comment))
+	}
+	if r.labelWidth > 0 {
+		width, height := zplLabelSize(in.zpl, dpmm)
+		if width == 0 || height == 0 {
+			return false, "label has no ^PW and ^LL size"
+		}
+		if math.Abs(width-r.labelWidth) > routeSizeTolerance || math.Abs(height-r.labelHeight) > routeSizeTolerance {
+			return false, fmt.Sprintf("label is %.2fx%.2f in, not %s", width, height, r.LabelSize)
+		}
+		met = append(met, "label size "+r.LabelSize)
+	}
+	if r.IsDefault {
+		return true, "the default rule matches every job no other rule took"
+	}
+	if len(met) == 0 {
+		return true, "rule has no conditions and matches every job"
+	}
+	return true, strings.Join(met, ", ")
+}
+
+// routeJob picks a destination for a job from the routing rules, falling back to the print mode
+func (a *App) routeJob(source string, listener string, zpl string) *RouteDecision {
+	in := routeInput{zpl: zpl}
+	host, _, err := net.SplitHostPort(source)
+	if err != nil {
+		host = source
+	}
+	in.sourceIP = net.ParseIP(host)
+	if u, err := url.Parse(listener); err == nil {
+		in.listenerPort, _ = strconv.Atoi(u.Port())
+	}
+
+	decision := &RouteDecision{Summary: "no rule matched"}
+	rules, err := loadedRoutingRules.get(a.db)
+	if err != nil {
+		fmt.Println("Error loading routing rules:", err)
+	}
+	for _, r := range rules {
+		matched, reason := r.match(in, a.Settings.PrinterDPI.Dpi)
+		decision.Checks = append(decision.Checks, RuleCheck{RuleID: r.RuleID, Name: r.Name, Matched: matched, Reason: reason})
+		if !matched {
+			continue
+		}
+		decision.RuleID = r.RuleID
+		decision.RuleName = r.Name
+		decision.Summary = fmt.Sprintf("rule %q matched (%s)", r.Name, reason)
+		if r.Action == RouteFollowMode {
+			break
+		}
+		decision.Action = r.Action
+		decision.TargetID = r.TargetID
+		decision.origin = fmt.Sprintf("rule %q", r.Name)
+		return decision
+	}
+
+	mode, printer, group := printSelection()
+	switch mode {
+	case 1:
+		decision.Action = RouteToPrinter
+		decision.TargetID = printer.PrinterID
+	case 2:
+		decision.Action = RouteToGroup
+		decision.TargetID = group.GroupID
+	default:
+		decision.Action = RouteToEmulator
+	}
+	decision.Summary += fmt.Sprintf(", using the %s print mode", printModeName(mode))
+	return decision
+}
+
+// fixedRoute is the decision for an input that has its own destination, such as a hot folder
+// mapped to a printer. Routing rules and the print mode are not consulted.
+func fixedRoute(origin string, action string, targetID int) *RouteDecision {
+	return &RouteDecision{
+		Action:   action,
+		TargetID: targetID,
+		Summary:  fmt.Sprintf("%s sets the destination", origin),
+		origin:   origin,
+	}
+}
+
+// validateFixedRoute checks the destination of an input that has its own, named by what
+func validateFixedRoute(what string, action string, targetID int) error {
+	switch action {
+	case RouteToPrinter, RouteToGroup:
+		if targetID <= 0 {
+			return fmt.Errorf("%s needs a %s to send to", what, action)
+		}
+	case RouteToEmulator:
+	default:
+		return fmt.Errorf("unknown action %q, use %s, %s or %s", action, RouteToPrinter, RouteToGroup, RouteToEmulator)
+	}
+	return nil
+}
+
+// routeTargets looks up the printer or relay group a decision sends to. The print mode's
+// targets are used as they are, since the forward printer need not be a saved one.
+func (a *App) routeTargets(d *RouteDecision) (Printer, RelayGroup, error) {
+	if d.origin == "" {
+		_, printer, group := printSelection()
+		return printer, group, nil
+	}
+	switch d.Action {
+	case RouteToPrinter:
+		printer, err := GetPrinterByID(a.db, d.TargetID)
+		if err != nil {
+			return Printer{}, RelayGroup{}, err
+		}
+		if printer == nil {
+			return Printer{}, RelayGroup{}, fmt.Errorf("%s: printer %d not found", d.origin, d.TargetID)
+		}
+		return *printer, RelayGroup{}, nil
+	case RouteToGroup:
+		group, err := GetRelayGroupByID(a.db, d.TargetID)
+		if err != nil {
+			return Printer{}, RelayGroup{}, err
+		}
+		if group == nil {
+			return Printer{}, RelayGroup{}, fmt.Errorf("%s: relay group %d not found", d.origin, d.TargetID)
+		}
+		return Printer{}, *group, nil
+	}
+	return Printer{}, RelayGroup{}, nil
+}
+
+// routeModeName is the mode stored with a job for a routing action
+func routeModeName(action string) string {
+	switch action {
+	case RouteToPrinter:
+		return "forward"
+	case RouteToGroup:
+		return "relay"
+	case RouteToEmulator:
+		return "emulate"
+	}
+	return action
+}
+
+// Routing rule methods for Wails frontend
+func (a *App) AddRoutingRule(rule RoutingRule) (int, error) {
+	defer loadedRoutingRules.reset()
+	return AddRoutingRule(a.db, rule)
+}
+
+func (a *App) UpdateRoutingRule(rule RoutingRule) error {
+	defer loadedRoutingRules.reset()
+	return UpdateRoutingRule(a.db, rule)
+}
+
+func (a *App) GetRoutingRules() ([]RoutingRule, error) {
+	return GetRoutingRules(a.db)
+}
+
+func (a *App) DeleteRoutingRule(ruleID int) error {
+	defer loadedRoutingRules.reset()
+	return DeleteRoutingRule(a.db, ruleID)
+}
+
+func (a *App) ReorderRoutingRules(ruleIDs []int) error {
+	defer loadedRoutingRules.reset()
+	return ReorderRoutingRules(a.db, ruleIDs)
+}
+
+// TestRoutingRule shows where a job from source (an IP address) arriving on listenerPort
+// would be sent, with the reason each rule did or did not match
+func (a *App) TestRoutingRule(source string, listenerPort int, zpl string) *RouteDecision {
+	return a.routeJob(source, "tcp://"+net.JoinHostPort(CONN_HOST, strconv.Itoa(listenerPort)), zpl)
+}
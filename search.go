@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+	"net"
+	"strings"
+)
+
+// JobSearchResult is a job whose label text matched a search
+type JobSearchResult struct {
+	Job     Job    `json:"job"`
+	Snippet string `json:"snippet"`
+}
+
+// Initialize the job_text full-text index. The trigram tokenizer lets a search
+// match any part of a tracking number or other field, not just whole words.
+func InitJobSearchTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS job_text USING fts5(
+			content,
+			jobID UNINDEXED,
+			receivedAt UNINDEXED,
+			sourceIP UNINDEXED,
+			tokenize = 'trigram'
+		)`)
+	if err != nil {
+		println("Error initializing job_text table:", err.Error())
+	}
+	return err
+}
+
+// IndexJobText stores the decoded ^FD field data of a job in the search index
+func IndexJobText(db *sql.DB, j *Job) error {
+	var lines []string
+	for _, f := range ExtractZPLFields(string(j.RawData)) {
+		if strings.TrimSpace(f.Data) != "" {
+			lines = append(lines, f.Data)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	sourceIP := j.SourceAddress
+	if host, _, err := net.SplitHostPort(j.SourceAddress); err == nil {
+		sourceIP = host
+	}
+	_, err := db.Exec(`INSERT INTO job_text (content, jobID, receivedAt, sourceIP) VALUES (?, ?, ?, ?)`,
+		strings.Join(lines, "\n"), j.JobID, j.ReceivedAt.UnixMilli(), sourceIP)
+	if err != nil {
+		println("Error indexing job text:", err.Error())
+	}
+	return err
+}
+
+// SearchJobs finds jobs whose label text contains every term in query, newest first
+func SearchJobs(db *sql.DB, query string, limit int) ([]JobSearchResult, error) {
+	match := ftsMatchExpression(query)
+	if match == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := db.Query(`
+		SELECT jobID, snippet(job_text, 0, '[', ']', '...', 16)
+		FROM job_text WHERE job_text MATCH ?
+		ORDER BY CAST(receivedAt AS INTEGER) DESC LIMIT ?
+	`, match, limit)
+	if err != nil {
+		println("Error searching jobs:", err.Error())
+		return nil, err
+	}
+	type hit struct {
+		jobID   int
+		snippet string
+	}
+	var hits []hit
+	for rows.Next() {
+		var h hit
+		if err := rows.Scan(&h.jobID, &h.snippet); err != nil {
+			println("Error scanning search row:", err.Error())
+			continue
+		}
+		hits = append(hits, h)
+	}
+	rows.Close()
+
+	var results []JobSearchResult
+	for _, h := range hits {
+		job, err := GetJobByID(db, h.jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			continue
+		}
+		results = append(results, JobSearchResult{Job: *job, Snippet: h.snippet})
+	}
+	return results, nil
+}
+
+// ftsMatchExpression quotes each search term so punctuation in label data
+// (dashes, slashes, quotes) is matched literally instead of as FTS syntax
+func ftsMatchExpression(query string) string {
+	var terms []string
+	for _, term := range strings.Fields(query) {
+		terms = append(terms, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BER tags and SNMP PDU types used by the agent
+const (
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berNull        = 0x05
+	berOID         = 0x06
+	berSequence    = 0x30
+	berCounter32   = 0x41
+	berGauge32     = 0x42
+	berTimeTicks   = 0x43
+
+	snmpGetRequest     = 0xA0
+	snmpGetNextRequest = 0xA1
+	snmpGetResponse    = 0xA2
+	snmpSetRequest     = 0xA3
+	snmpGetBulkRequest = 0xA5
+
+	snmpVersion1  = 0
+	snmpVersion2c = 1
+
+	snmpNoSuchObject = 0x80
+	snmpEndOfMib     = 0x82
+
+	snmpErrNoSuchName  = 2
+	snmpErrReadOnly    = 4
+	snmpErrNotWritable = 17
+)
+
+// Zebra's IANA enterprise number, used for sysObjectID and the Zebra MIB branch
+const zebraEnterpriseOID = "1.3.6.1.4.1.10642"
+
+// snmpValue is an encoded BER value for a single MIB object
+type snmpValue struct {
+	tag  byte
+	data []byte
+}
+
+// snmpObject is one scalar in the emulated MIB
+type snmpObject struct {
+	oid   []int
+	value func() snmpValue
+}
+
+// The standard SNMP port 161 needs root or administrator rights, so the agent defaults to the
+// common unprivileged alternative. Monitoring tools must be pointed at this port.
+const defaultSNMPPort = 1161
+
+// SNMPAgent answers SNMP v1/v2c requests about the emulated printer
+type SNMPAgent struct {
+	conn      *net.UDPConn
+	quit      chan any
+	wg        sync.WaitGroup
+	community string
+	objects   []snmpObject
+}
+
+// NewSNMPAgent starts an SNMP responder on the configured address and port. It does not
+// follow the printer's bind address: fleet tools poll from other machines, so the agent
+// listens on all interfaces unless an address is set.
+func (a *App) NewSNMPAgent() (*SNMPAgent, error) {
+	port := a.Settings.SNMPPort
+	if port == 0 {
+		port = defaultSNMPPort
+	}
+	community := a.Settings.SNMPCommunity
+	if community == "" {
+		community = "public"
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(a.Settings.SNMPBind, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start SNMP agent: %w", err)
+	}
+
+	s := &SNMPAgent{
+		conn:      conn,
+		quit:      make(chan any),
+		community: community,
+		objects:   a.snmpObjects(),
+	}
+	s.wg.Add(1)
+	go s.serve()
+
+	fmt.Printf("SNMP agent listening on %s\n", addr.String())
+	return s, nil
+}
+
+// Stop shuts the SNMP agent down
+func (s *SNMPAgent) Stop() {
+	close(s.quit)
+	s.conn.Close()
+	waitTimeout(&s.wg, 1*time.Second)
+}
+
+// snmpObjects builds the emulated MIB from EmulatorStats and the printer settings, which are
+// read on every request so changes show up without restarting the agent
+func (a *App) snmpObjects() []snmpObject {
+	host, _ := os.Hostname()
+	model := "ZPL Printer Emulator"
+	zebraModel := func(settings *Settings) string {
+		dpi := int(float64(settings.PrinterDPI.Dpi)*25.4 + 0.5)
+		return fmt.Sprintf("ZPL-Emulator-%ddpi", dpi)
+	}
+	sysDescr := func() string {
+		settings := a.Settings
+		return fmt.Sprintf("%s %s, %s, %gx%gin", model, AppVersion, zebraModel(settings), settings.PrintWidth, settings.PrintHeight)
+	}
+
+	str := func(v string) func() snmpValue {
+		return func() snmpValue { return snmpValue{berOctetString, []byte(v)} }
+	}
+	text := func(f func() string) func() snmpValue {
+		return func() snmpValue { return snmpValue{berOctetString, []byte(f())} }
+	}
+	integer := func(f func() int64) func() snmpValue {
+		return func() snmpValue { return snmpValue{berInteger, berEncodeInt(f())} }
+	}
+	counter := func(f func() int64) func() snmpValue {
+		return func() snmpValue { return snmpValue{berCounter32, berEncodeUint(uint32(f()))} }
+	}
+
+	objects := []snmpObject{
+		// SNMPv2-MIB system group
+		{mustParseOID("1.3.6.1.2.1.1.1.0"), text(sysDescr)},
+		{mustParseOID("1.3.6.1.2.1.1.2.0"), func() snmpValue {
+			return snmpValue{berOID, berEncodeOID(mustParseOID(zebraEnterpriseOID + ".1"))}
+		}},
+		{mustParseOID("1.3.6.1.2.1.1.3.0"), func() snmpValue {
+			uptime := time.Since(EmulatorStats.Snapshot().StartedAt) / (10 * time.Millisecond)
+			return snmpValue{berTimeTicks, berEncodeUint(uint32(uptime))}
+		}},
+		{mustParseOID("1.3.6.1.2.1.1.4.0"), str("")},
+		{mustParseOID("1.3.6.1.2.1.1.5.0"), str(host)},
+		{mustParseOID("1.3.6.1.2.1.1.6.0"), str("")},
+		{mustParseOID("1.3.6.1.2.1.1.7.0"), integer(func() int64 { return 72 })},
+
+		// HOST-RESOURCES-MIB hrDeviceTable / hrPrinterTable, device index 1
+		{mustParseOID("1.3.6.1.2.1.25.3.2.1.2.1"), func() snmpValue {
+			return snmpValue{berOID, berEncodeOID(mustParseOID("1.3.6.1.2.1.25.3.1.5"))} // hrDevicePrinter
+		}},
+		{mustParseOID("1.3.6.1.2.1.25.3.2.1.3.1"), str(model)},
+		{mustParseOID("1.3.6.1.2.1.25.3.2.1.5.1"), integer(func() int64 {
+			if !Running {
+				return 5 // down
+			}
+			if EmulatorStats.Snapshot().LastError != "" {
+				return 3 // warning
+			}
+			return 2 // running
+		})},
+		{mustParseOID("1.3.6.1.2.1.25.3.5.1.1.1"), integer(func() int64 {
+			if !Running {
+				return 1 // other
+			}
+			if EmulatorStats.Snapshot().ActiveJobs > 0 {
+				return 4 // printing
+			}
+			return 3 // idle
+		})},
+		{mustParseOID("1.3.6.1.2.1.25.3.5.1.2.1"), func() snmpValue {
+			if !Running {
+				return snmpValue{berOctetString, []byte{0x02}} // offline
+			}
+			return snmpValue{berOctetString, []byte{0x00}}
+		}},
+
+		// Printer-MIB
+		{mustParseOID("1.3.6.1.2.1.43.5.1.1.16.1"), str(model)},
+		{mustParseOID("1.3.6.1.2.1.43.10.2.1.4.1.1"), counter(func() int64 { return EmulatorStats.Snapshot().LabelsPrinted })},
+
+		// Zebra enterprise branch: model, firmware, job counters
+		{mustParseOID(zebraEnterpriseOID + ".1.1.0"), text(func() string { return zebraModel(a.Settings) })},
+		{mustParseOID(zebraEnterpriseOID + ".1.2.0"), str("V" + AppVersion)},
+		{mustParseOID(zebraEnterpriseOID + ".1.3.0"), counter(func() int64 { return EmulatorStats.Snapshot().JobsReceived })},
+		{mustParseOID(zebraEnterpriseOID + ".1.4.0"), counter(func() int64 { return EmulatorStats.Snapshot().BytesReceived })},
+		{mustParseOID(zebraEnterpriseOID + ".1.5.0"), counter(func() int64 { return EmulatorStats.Snapshot().LabelsPrinted })},
+	}
+	sort.Slice(objects, func(i, j int) bool { return compareOIDs(objects[i].oid, objects[j].oid) < 0 })
+	return objects
+}
+
+func (s *SNMPAgent) serve() {
+	defer s.wg.Done()
+
+	buf := make([]byte, 65535)
+	for {
+		n, src, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+				log.Println("SNMP read error", err)
+				continue
+			}
+		}
+		resp, err := s.handlePacket(buf[:n])
+		if err != nil {
+			log.Println("SNMP request error", err)
+			continue
+		}
+		if resp != nil {
+			s.conn.WriteToUDP(resp, src)
+		}
+	}
+}
+
+// handlePacket decodes one SNMP message and returns the encoded response, if any
+func (s *SNMPAgent) handlePacket(packet []byte) ([]byte, error) {
+	tag, msg, _, err := berReadTLV(packet)
+	if err != nil || tag != berSequence {
+		return nil, fmt.Errorf("malformed message")
+	}
+	_, versionBytes, rest, err := berReadTLV(msg)
+	if err != nil {
+		return nil, err
+	}
+	version := berDecodeInt(versionBytes)
+	if version != snmpVersion1 && version != snmpVersion2c {
+		return nil, fmt.Errorf("unsupported SNMP version %d", version)
+	}
+	_, community, rest, err := berReadTLV(rest)
+	if err != nil {
+		return nil, err
+	}
+	if string(community) != s.community {
+		// Wrong community strings are silently dropped, as real agents do
+		return nil, nil
+	}
+	pduType, pdu, _, err := berReadTLV(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	_, reqIDBytes, pdu, err := berReadTLV(pdu)
+	if err != nil {
+		return nil, err
+	}
+	_, field1, pdu, err := berReadTLV(pdu)
+	if err != nil {
+		return nil, err
+	}
+	_, field2, pdu, err := berReadTLV(pdu)
+	if err != nil {
+		return nil, err
+	}
+	_, varbindList, _, err := berReadTLV(pdu)
+	if err != nil {
+		return nil, err
+	}
+
+	var oids [][]int
+	for len(varbindList) > 0 {
+		var vb []byte
+		_, vb, varbindList, err = berReadTLV(varbindList)
+		if err != nil {
+			return nil, err
+		}
+		_, oidBytes, _, err := berReadTLV(vb)
+		if err != nil {
+			return nil, err
+		}
+		oids = append(oids, berDecodeOID(oidBytes))
+	}
+
+	var results []snmpVarbind
+	errStatus, errIndex := 0, 0
+	switch pduType {
+	case snmpGetRequest:
+		for i, oid := range oids {
+			obj := s.find(oid)
+			if obj == nil {
+				if version == snmpVersion1 {
+					errStatus, errIndex = snmpErrNoSuchName, i+1
+					results = nil
+					break
+				}
+				results = append(results, snmpVarbind{oid, snmpValue{snmpNoSuchObject, nil}})
+				continue
+			}
+			results = append(results, snmpVarbind{oid, obj.value()})
+		}
+	case snmpGetNextRequest:
+		for i, oid := range oids {
+			obj := s.next(oid)
+			if obj == nil {
+				if version == snmpVersion1 {
+					errStatus, errIndex = snmpErrNoSuchName, i+1
+					results = nil
+					break
+				}
+				results = append(results, snmpVarbind{oid, snmpValue{snmpEndOfMib, nil}})
+				continue
+			}
+			results = append(results, snmpVarbind{obj.oid, obj.value()})
+		}
+	case snmpGetBulkRequest:
+		if version == snmpVersion1 {
+			return nil, fmt.Errorf("GetBulk is not valid in SNMPv1")
+		}
+		nonRepeaters := int(berDecodeInt(field1))
+		maxRepetitions := int(berDecodeInt(field2))
+		results = s.getBulk(oids, nonRepeaters, maxRepetitions)
+	case snmpSetRequest:
+		errStatus, errIndex = snmpErrNotWritable, 1
+		if version == snmpVersion1 {
+			errStatus = snmpErrReadOnly
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PDU type 0x%02x", pduType)
+	}
+
+	// Errors echo the request varbinds unchanged
+	if errStatus != 0 {
+		results = nil
+		for _, oid := range oids {
+			results = append(results, snmpVarbind{oid, snmpValue{berNull, nil}})
+		}
+	}
+	return encodeSNMPResponse(version, community, berDecodeInt(reqIDBytes), errStatus, errIndex, results), nil
+}
+
+// snmpVarbind pairs an OID with its value in a response
+type snmpVarbind struct {
+	oid   []int
+	value snmpValue
+}
+
+func (s *SNMPAgent) find(oid []int) *snmpObject {
+	for i := range s.objects {
+		if compareOIDs(s.objects[i].oid, oid) == 0 {
+			return &s.objects[i]
+		}
+	}
+	return nil
+}
+
+func (s *SNMPAgent) next(oid []int) *snmpObject {
+	for i := range s.objects {
+		if compareOIDs(s.objects[i].oid, oid) > 0 {
+			return &s.objects[i]
+		}
+	}
+	return nil
+}
+
+func (s *SNMPAgent) getBulk(oids [][]int, nonRepeaters int, maxRepetitions int) []snmpVarbind {
+	if nonRepeaters < 0 {
+		nonRepeaters = 0
+	}
+	if nonRepeaters > len(oids) {
+		nonRepeaters = len(oids)
+	}
+	if maxRepetitions < 0 {
+		maxRepetitions = 0
+	}
+	if maxRepetitions > len(s.objects) {
+		maxRepetitions = len(s.objects)
+	}
+
+	var results []snmpVarbind
+	nextVarbind := func(oid []int) snmpVarbind {
+		obj := s.next(oid)
+		if obj == nil {
+			return snmpVarbind{oid, snmpValue{snmpEndOfMib, nil}}
+		}
+		return snmpVarbind{obj.oid, obj.value()}
+	}
+	for _, oid := range oids[:nonRepeaters] {
+		results = append(results, nextVarbind(oid))
+	}
+	cursors := append([][]int{}, oids[nonRepeaters:]...)
+	for r := 0; r < maxRepetitions && len(cursors) > 0; r++ {
+		for i, oid := range cursors {
+			vb := nextVarbind(oid)
+			results = append(results, vb)
+			cursors[i] = vb.oid
+		}
+	}
+	return results
+}
+
+func encodeSNMPResponse(version int64, community []byte, requestID int64, errStatus int, errIndex int, varbinds []snmpVarbind) []byte {
+	var vbList bytes.Buffer
+	for _, vb := range varbinds {
+		var item bytes.Buffer
+		berWriteTLV(&item, berOID, berEncodeOID(vb.oid))
+		berWriteTLV(&item, vb.value.tag, vb.value.data)
+		berWriteTLV(&vbList, berSequence, item.Bytes())
+	}
+
+	var pdu bytes.Buffer
+	berWriteTLV(&pdu, berInteger, berEncodeInt(requestID))
+	berWriteTLV(&pdu, berInteger, berEncodeInt(int64(errStatus)))
+	berWriteTLV(&pdu, berInteger, berEncodeInt(int64(errIndex)))
+	berWriteTLV(&pdu, berSequence, vbList.Bytes())
+
+	var msg bytes.Buffer
+	berWriteTLV(&msg, berInteger, berEncodeInt(version))
+	berWriteTLV(&msg, berOctetString, community)
+	berWriteTLV(&msg, snmpGetResponse, pdu.Bytes())
+
+	var out bytes.Buffer
+	berWriteTLV(&out, berSequence, msg.Bytes())
+	return out.Bytes()
+}
+
+// berReadTLV reads one tag-length-value and returns the value and the remaining bytes
+func berReadTLV(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, nil, fmt.Errorf("truncated BER value")
+	}
+	tag := data[0]
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		numBytes := length & 0x7F
+		if numBytes == 0 || numBytes > 4 || len(data) < 2+numBytes {
+			return 0, nil, nil, fmt.Errorf("invalid BER length")
+		}
+		length = 0
+		for _, b := range data[2 : 2+numBytes] {
+			length = length<<8 | int(b)
+		}
+		offset += numBytes
+	}
+	if length < 0 || len(data) < offset+length {
+		return 0, nil, nil, fmt.Errorf("truncated BER value")
+	}
+	return tag, data[offset : offset+length], data[offset+length:], nil
+}
+
+func berWriteTLV(buf *bytes.Buffer, tag byte, value []byte) {
+	buf.WriteByte(tag)
+	length := len(value)
+	switch {
+	case length < 0x80:
+		buf.WriteByte(byte(length))
+	case length <= 0xFF:
+		buf.WriteByte(0x81)
+		buf.WriteByte(byte(length))
+	default:
+		buf.WriteByte(0x82)
+		buf.WriteByte(byte(length >> 8))
+		buf.WriteByte(byte(length))
+	}
+	buf.Write(value)
+}
+
+func berEncodeInt(v int64) []byte {
+	var out []byte
+	for {
+		out = append([]byte{byte(v)}, out...)
+		v >>= 8
+		if (v == 0 && out[0]&0x80 == 0) || (v == -1 && out[0]&0x80 != 0) {
+			return out
+		}
+	}
+}
+
+func berEncodeUint(v uint32) []byte {
+	return berEncodeInt(int64(v))
+}
+
+func berDecodeInt(data []byte) int64 {
+	var v int64
+	for i, b := range data {
+		if i == 0 && b&0x80 != 0 {
+			v = -1
+		}
+		v = v<<8 | int64(b)
+	}
+	return v
+}
+
+func berEncodeOID(oid []int) []byte {
+	if len(oid) < 2 {
+		return []byte{0}
+	}
+	out := []byte{byte(oid[0]*40 + oid[1])}
+	for _, component := range oid[2:] {
+		var chunk []byte
+		chunk = append(chunk, byte(component&0x7F))
+		component >>= 7
+		for component > 0 {
+			chunk = append([]byte{byte(component&0x7F) | 0x80}, chunk...)
+			component >>= 7
+		}
+		out = append(out, chunk...)
+	}
+	return out
+}
+
+func berDecodeOID(data []byte) []int {
+	if len(data) == 0 {
+		return nil
+	}
+	oid := []int{int(data[0]) / 40, int(data[0]) % 40}
+	value := 0
+	for _, b := range data[1:] {
+		value = value<<7 | int(b&0x7F)
+		if b&0x80 == 0 {
+			oid = append(oid, value)
+			value = 0
+		}
+	}
+	return oid
+}
+
+func mustParseOID(s string) []int {
+	parts := strings.Split(strings.TrimPrefix(s, "."), ".")
+	oid := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			panic(fmt.Sprintf("invalid OID %q", s))
+		}
+		oid[i] = n
+	}
+	return oid
+}
+
+func compareOIDs(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
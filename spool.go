@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Bounds for the on-disk job spool
+const (
+	spoolMaxFiles = 500
+	spoolMaxBytes = 256 * 1024 * 1024
+)
+
+// JobSpool keeps the raw bytes of recently received jobs on disk so they can be reprinted
+type JobSpool struct {
+	dir      string
+	maxFiles int
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// NewJobSpool creates the spool directory if needed
+func NewJobSpool(dir string, maxFiles int, maxBytes int64) (*JobSpool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating spool directory: %w", err)
+	}
+	return &JobSpool{dir: dir, maxFiles: maxFiles, maxBytes: maxBytes}, nil
+}
+
+func (s *JobSpool) path(jobID int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("job-%08d.prn", jobID))
+}
+
+// Save writes a job's raw data and evicts the oldest jobs beyond the spool limits
+func (s *JobSpool) Save(jobID int, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.WriteFile(s.path(jobID), data, 0644); err != nil {
+		return err
+	}
+	return s.trim()
+}
+
+// Load returns a spooled job's raw data, or os.ErrNotExist once it has been evicted
+func (s *JobSpool) Load(jobID int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.ReadFile(s.path(jobID))
+}
+
+// Remove deletes a job from the spool if present
+func (s *JobSpool) Remove(jobID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.path(jobID))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// trim removes the oldest spooled jobs until the spool is within its limits.
+// File names embed the zero-padded job ID, so name order is arrival order.
+func (s *JobSpool) trim() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	type spooled struct {
+		name string
+		size int64
+	}
+	var files []spooled
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "job-") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, spooled{e.Name(), info.Size()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	for len(files) > 0 && (len(files) > s.maxFiles || total > s.maxBytes) {
+		if err := os.Remove(filepath.Join(s.dir, files[0].name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= files[0].size
+		files = files[1:]
+	}
+	return nil
+}
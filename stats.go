@@ -0,0 +1,65 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// PrinterStats tracks activity on the emulated printer so status reporters
+// (SNMP, the frontend) see the same state as the TCP listener
+type PrinterStats struct {
+	mu            sync.Mutex
+	StartedAt     time.Time `json:"startedAt"`
+	JobsReceived  int64     `json:"jobsReceived"`
+	BytesReceived int64     `json:"bytesReceived"`
+	LabelsPrinted int64     `json:"labelsPrinted"`
+	ActiveJobs    int       `json:"activeJobs"`
+	LastJobTime   time.Time `json:"lastJobTime"`
+	LastError     string    `json:"lastError"`
+}
+
+var EmulatorStats = &PrinterStats{StartedAt: time.Now()}
+
+// JobStarted records a new job arriving on a listener
+func (s *PrinterStats) JobStarted() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ActiveJobs++
+}
+
+// JobFinished records the end of a job and its size in bytes
+func (s *PrinterStats) JobFinished(bytes int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ActiveJobs > 0 {
+		s.ActiveJobs--
+	}
+	s.JobsReceived++
+	s.BytesReceived += int64(bytes)
+	s.LastJobTime = time.Now()
+	if err != nil {
+		s.LastError = err.Error()
+	}
+}
+
+// LabelsRendered adds to the count of labels produced by the emulator
+func (s *PrinterStats) LabelsRendered(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.LabelsPrinted += int64(count)
+}
+
+// Snapshot returns a copy of the current counters
+func (s *PrinterStats) Snapshot() PrinterStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return PrinterStats{
+		StartedAt:     s.StartedAt,
+		JobsReceived:  s.JobsReceived,
+		BytesReceived: s.BytesReceived,
+		LabelsPrinted: s.LabelsPrinted,
+		ActiveJobs:    s.ActiveJobs,
+		LastJobTime:   s.LastJobTime,
+		LastError:     s.LastError,
+	}
+}
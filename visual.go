@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Golden comparison statuses
+const (
+	GoldenPass         = "pass"
+	GoldenFail         = "fail"
+	GoldenMissing      = "missing"
+	GoldenSizeMismatch = "size-mismatch"
+)
+
+// regionMergeGap joins changed areas closer than this many pixels into one bounding box
+const regionMergeGap = 4
+
+// goldenMarker names the golden image a label is checked against in a ^FX comment, e.g.
+// ^FXgolden=shipping-4x6^FS. Field data is not searched.
+var goldenMarker = regexp.MustCompile(`(?i)golden\s*[=:]\s*([A-Za-z0-9._-]+)`)
+
+var goldenNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// CompareOptions controls how strict a golden comparison is
+type CompareOptions struct {
+	ColorTolerance int `json:"colorTolerance"` // per-channel difference (0-255) still treated as equal
+	MaxDiffPixels  int `json:"maxDiffPixels"`  // changed pixels allowed before the comparison fails
+}
+
+func (o CompareOptions) validate() error {
+	if o.ColorTolerance < 0 || o.ColorTolerance > 255 {
+		return fmt.Errorf("color tolerance %d is out of range, use 0-255", o.ColorTolerance)
+	}
+	if o.MaxDiffPixels < 0 {
+		return fmt.Errorf("allowed changed pixels must not be negative")
+	}
+	return nil
+}
+
+// DiffRegion is the bounding box of a changed area, in label pixels
+type DiffRegion struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// CompareResult is the outcome of checking one rendered label against its golden image
+type CompareResult struct {
+	Name       string       `json:"name"`
+	LabelIndex int          `json:"labelIndex"`
+	Status     string       `json:"status"`
+	Passed     bool         `json:"passed"`
+	DiffPixels int          `json:"diffPixels"`
+	DiffRatio  float64      `json:"diffRatio"`
+	Regions    []DiffRegion `json:"regions"`
+	DiffImage  []byte       `json:"diffImage,omitempty"`
+	Error      string       `json:"error"`
+}
+
+// GoldenStore keeps reference label images on disk, one PNG per name
+type GoldenStore struct {
+	dir string
+	mu  sync.Mutex
+
+	// latest holds the comparisons from the most recent job that had any
+	latest []CompareResult
+}
+
+// NewGoldenStore creates the golden image directory if needed
+func NewGoldenStore(dir string) (*GoldenStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating golden image directory: %w", err)
+	}
+	return &GoldenStore{dir: dir}, nil
+}
+
+func (g *GoldenStore) path(name string) (string, error) {
+	if !goldenNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid golden name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return filepath.Join(g.dir, name+".png"), nil
+}
+
+// Save stores pngBytes as the golden image for name, replacing any existing one
+func (g *GoldenStore) Save(name string, pngBytes []byte) error {
+	path, err := g.path(name)
+	if err != nil {
+		return err
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(pngBytes)); err != nil {
+		return fmt.Errorf("golden image is not a valid PNG: %w", err)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return os.WriteFile(path, pngBytes, 0644)
+}
+
+// Load returns the golden PNG for name, or os.ErrNotExist
+func (g *GoldenStore) Load(name string) ([]byte, error) {
+	path, err := g.path(name)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return os.ReadFile(path)
+}
+
+// Delete removes a golden image
+func (g *GoldenStore) Delete(name string) error {
+	path, err := g.path(name)
+	if err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return os.Remove(path)
+}
+
+// List returns the names of all golden images
+func (g *GoldenStore) List() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(g.dir, "*.png"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, m := range matches {
+		names = append(names, strings.TrimSuffix(filepath.Base(m), ".png"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (g *GoldenStore) setLatest(results []CompareResult) {
+	g.mu.Lock()
+	g.latest = results
+	g.mu.Unlock()
+}
+
+// Latest returns the comparisons from the most recent job that had any
+func (g *GoldenStore) Latest() []CompareResult {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.latest
+}
+
+// Compare checks a rendered label against the golden image stored under name
+func (g *GoldenStore) Compare(name string, labelIndex int, actualPNG []byte, opts CompareOptions) CompareResult {
+	result := CompareResult{Name: name, LabelIndex: labelIndex}
+	goldenPNG, err := g.Load(name)
+	if err != nil {
+		result.Status = GoldenMissing
+		if !os.IsNotExist(err) {
+			result.Error = err.Error()
+		}
+		return result
+	}
+	golden, err := png.Decode(bytes.NewReader(goldenPNG))
+	if err != nil {
+		result.Status = GoldenFail
+		result.Error = fmt.Sprintf("failed to decode golden image: %v", err)
+		return result
+	}
+	actual, err := png.Decode(bytes.NewReader(actualPNG))
+	if err != nil {
+		result.Status = GoldenFail
+		result.Error = fmt.Sprintf("failed to decode rendered label: %v", err)
+		return result
+	}
+	cmp := CompareImages(golden, actual, opts)
+	cmp.Name = name
+	cmp.LabelIndex = labelIndex
+	return cmp
+}
+
+// CompareImages diffs two images pixel by pixel and reports the changed regions
+func CompareImages(golden image.Image, actual image.Image, opts CompareOptions) CompareResult {
+	gb, ab := golden.Bounds(), actual.Bounds()
+	if gb.Dx() != ab.Dx() || gb.Dy() != ab.Dy() {
+		return CompareResult{
+			Status: GoldenSizeMismatch,
+			Error:  fmt.Sprintf("golden is %dx%d, rendered label is %dx%d", gb.Dx(), gb.Dy(), ab.Dx(), ab.Dy()),
+		}
+	}
+
+	width, height := ab.Dx(), ab.Dy()
+	mask := make([]bool, width*height)
+	diffImage := image.NewRGBA(image.Rect(0, 0, width, height))
+	diffPixels := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			gc := color.RGBAModel.Convert(golden.At(gb.Min.X+x, gb.Min.Y+y)).(color.RGBA)
+			ac := color.RGBAModel.Convert(actual.At(ab.Min.X+x, ab.Min.Y+y)).(color.RGBA)
+			if pixelsDiffer(gc, ac, opts.ColorTolerance) {
+				mask[y*width+x] = true
+				diffPixels++
+				diffImage.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+			// Unchanged pixels are drawn faded so the red changes stand out
+			gray := uint8((int(ac.R) + int(ac.G) + int(ac.B)) / 3)
+			faded := 255 - (255-gray)/4
+			diffImage.SetRGBA(x, y, color.RGBA{R: faded, G: faded, B: faded, A: 255})
+		}
+	}
+
+	result := CompareResult{
+		DiffPixels: diffPixels,
+		Status:     GoldenPass,
+		Passed:     diffPixels <= opts.MaxDiffPixels,
+	}
+	if width*height > 0 {
+		result.DiffRatio = float64(diffPixels) / float64(width*height)
+	}
+	if diffPixels > 0 {
+		result.Regions = diffRegions(mask, width, height)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, diffImage); err == nil {
+			result.DiffImage = buf.Bytes()
+		}
+	}
+	if !result.Passed {
+		result.Status = GoldenFail
+	}
+	return result
+}
+
+func pixelsDiffer(a, b color.RGBA, tolerance int) bool {
+	diff := func(x, y uint8) int {
+		if x > y {
+			return int(x - y)
+		}
+		return int(y - x)
+	}
+	return diff(a.R, b.R) > tolerance || diff(a.G, b.G) > tolerance ||
+		diff(a.B, b.B) > tolerance || diff(a.A, b.A) > tolerance
+}
+
+// diffRegions groups changed pixels into connected areas and returns their bounding boxes,
+// merging boxes that lie within regionMergeGap of each other
+func diffRegions(mask []bool, width, height int) []DiffRegion {
+	visited := make([]bool, len(mask))
+	var boxes []image.Rectangle
+	var stack []int
+	for start := range mask {
+		if !mask[start] || visited[start] {
+			continue
+		}
+		box := image.Rect(start%width, start/width, start%width+1, start/width+1)
+		visited[start] = true
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			px, py := p%width, p/width
+			box = box.Union(image.Rect(px, py, px+1, py+1))
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := px+dx, py+dy
+					if nx < 0 || ny < 0 || nx >= width || ny >= height {
+						continue
+					}
+					n := ny*width + nx
+					if mask[n] && !visited[n] {
+						visited[n] = true
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+		boxes = append(boxes, box)
+	}
+
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(boxes) && !merged; i++ {
+			grown := boxes[i].Inset(-regionMergeGap)
+			for j := i + 1; j < len(boxes); j++ {
+				if grown.Overlaps(boxes[j]) {
+					boxes[i] = boxes[i].Union(boxes[j])
+					boxes = append(boxes[:j], boxes[j+1:]...)
+					merged = true
+					break
+				}
+			}
+		}
+	}
+
+	regions := make([]DiffRegion, 0, len(boxes))
+	for _, b := range boxes {
+		regions = append(regions, DiffRegion{X: b.Min.X, Y: b.Min.Y, Width: b.Dx(), Height: b.Dy()})
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		if regions[i].Y != regions[j].Y {
+			return regions[i].Y < regions[j].Y
+		}
+		return regions[i].X < regions[j].X
+	})
+	return regions
+}
+
+// goldenNamesForLabels finds the golden marker of each ^XA...^XZ block and maps it onto
+// the rendered images. Labels without a marker get an empty name and are not compared.
+func goldenNamesForLabels(zpl string, imageCount int) []string {
+	var blocks []string
+	for _, block := range splitZPLLabels(zpl) {
+		name := ""
+		for _, comment := range zplComments(block) {
+			if m := goldenMarker.FindStringSubmatch(comment); m != nil {
+				name = m[1]
+				break
+			}
+		}
+		blocks = append(blocks, name)
+	}
+
+	names := make([]string, imageCount)
+	if len(blocks) == imageCount {
+		copy(names, blocks)
+		return names
+	}
+	// Quantities (^PQ) and serialization produce more images than blocks; number them instead
+	if len(blocks) > 0 && blocks[0] != "" {
+		for i := range names {
+			names[i] = blocks[0]
+			if i > 0 {
+				names[i] = fmt.Sprintf("%s-%d", blocks[0], i+1)
+			}
+		}
+	}
+	return names
+}
+
+// splitZPLLabels returns the text of each ^XA...^XZ block
+func splitZPLLabels(zpl string) []string {
+	var labels []string
+	upper := strings.ToUpper(zpl)
+	for {
+		start := strings.Index(upper, "^XA")
+		if start < 0 {
+			return labels
+		}
+		end := strings.Index(upper[start:], "^XZ")
+		if end < 0 {
+			return append(labels, zpl[start:])
+		}
+		end += start + len("^XZ")
+		labels = append(labels, zpl[start:end])
+		zpl, upper = zpl[end:], upper[end:]
+	}
+}
+
+// checkGoldens compares every rendered label that carries a golden marker and emits the results
+func (a *App) checkGoldens(zpl string, images [][]byte) []CompareResult {
+	if a.goldens == nil {
+		return nil
+	}
+	var results []CompareResult
+	for i, name := range goldenNamesForLabels(zpl, len(images)) {
+		if name == "" {
+			continue
+		}
+		result := a.goldens.Compare(name, i, images[i], a.Settings.GoldenCompare)
+		a.ui.Emit("GoldenCompared", result)
+		results = append(results, result)
+	}
+	if len(results) > 0 {
+		a.goldens.setLatest(results)
+	}
+	return results
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// ZPLCommand is a single ^ or ~ command with its raw parameter text
+type ZPLCommand struct {
+	Prefix byte   `json:"prefix"`
+	Name   string `json:"name"`
+	Params string `json:"params"`
+}
+
+// ZPLField is the decoded data of one ^FD/^FV field
+type ZPLField struct {
+	Label int    `json:"label"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Data  string `json:"data"`
+}
+
+// ParseZPL splits a ZPL stream into commands. Line breaks are ignored like the printer does,
+// and ^CC/^CT prefix changes are honoured.
+func ParseZPL(zpl string) []ZPLCommand {
+	zpl = strings.NewReplacer("\r", "", "\n", "").Replace(zpl)
+	caret, tilde := byte('^'), byte('~')
+
+	var commands []ZPLCommand
+	i := 0
+	for i < len(zpl) {
+		if zpl[i] != caret && zpl[i] != tilde {
+			i++
+			continue
+		}
+		prefix := zpl[i]
+		i++
+
+		// ^A takes the font name as its first parameter rather than as part of the command
+		nameLen := 2
+		if prefix == caret && i < len(zpl) && (zpl[i] == 'A' || zpl[i] == 'a') {
+			nameLen = 1
+		}
+		if i+nameLen > len(zpl) {
+			break
+		}
+		name := strings.ToUpper(zpl[i : i+nameLen])
+		i += nameLen
+
+		// Field data may contain the tilde character, so only a caret ends it
+		start := i
+		for i < len(zpl) {
+			if zpl[i] == caret || (zpl[i] == tilde && !zplTakesFieldData(name)) {
+				break
+			}
+			i++
+		}
+		cmd := ZPLCommand{Prefix: '^', Name: name, Params: zpl[start:i]}
+		if prefix == tilde {
+			cmd.Prefix = '~'
+		}
+		commands = append(commands, cmd)
+
+		switch name {
+		case "CC":
+			if len(cmd.Params) > 0 {
+				caret = cmd.Params[0]
+			}
+		case "CT":
+			if len(cmd.Params) > 0 {
+				tilde = cmd.Params[0]
+			}
+		}
+	}
+	return commands
+}
+
+// zplTakesFieldData reports whether a command's parameters are free text
+func zplTakesFieldData(name string) bool {
+	return name == "FD" || name == "FV" || name == "FX"
+}
+
+// ExtractZPLFields returns the decoded text of every field in the stream, applying
+// ^FH hex escapes and the ^CI character set in effect for each field
+func ExtractZPLFields(zpl string) []ZPLField {
+	var fields []ZPLField
+	label := 0
+	charset := 0
+	x, y := 0, 0
+	hexIndicator := byte(0)
+	for _, cmd := range ParseZPL(zpl) {
+		switch cmd.Name {
+		case "XA":
+			label++
+			x, y = 0, 0
+		case "FO", "FT":
+			params := zplParams(cmd.Params)
+			x, y = zplParamInt(params, 0, 0), zplParamInt(params, 1, 0)
+		case "FH":
+			hexIndicator = '_'
+			if cmd.Params != "" {
+				hexIndicator = cmd.Params[0]
+			}
+		case "CI":
+			charset = zplParamInt(zplParams(cmd.Params), 0, 0)
+		case "FD", "FV":
+			data := []byte(cmd.Params)
+			if hexIndicator != 0 {
+				data = decodeZPLHex(data, hexIndicator)
+			}
+			fields = append(fields, ZPLField{Label: label, X: x, Y: y, Data: decodeZPLText(data, charset)})
+		case "FS":
+			hexIndicator = 0
+		}
+	}
+	return fields
+}
+
+// decodeZPLHex replaces indicator-prefixed hex pairs (e.g. _41) with the byte they encode
+func decodeZPLHex(data []byte, indicator byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == indicator && i+2 < len(data) {
+			if v, err := strconv.ParseUint(string(data[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
+
+// decodeZPLText converts field bytes to a string according to the ^CI character set
+func decodeZPLText(data []byte, charset int) string {
+	var enc encoding.Encoding
+	switch charset {
+	case 28:
+		if utf8.Valid(data) {
+			return string(data)
+		}
+		enc = charmap.Windows1252
+	case 29:
+		enc = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case 30:
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case 27:
+		enc = charmap.Windows1252
+	case 31:
+		enc = charmap.Windows1255
+	default:
+		// ^CI0-13 are Zebra's single byte sets; their upper half follows code page 850
+		enc = charmap.CodePage850
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// zplParams splits a comma separated parameter list
+func zplParams(params string) []string {
+	if params == "" {
+		return nil
+	}
+	return strings.Split(params, ",")
+}
+
+// zplParamInt returns the integer at index, or def when missing or invalid
+func zplParamInt(params []string, index int, def int) int {
+	if index >= len(params) {
+		return def
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(params[index]))
+	if err != nil {
+		return def
+	}
+	return v
+}
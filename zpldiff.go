@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ZPLElement is one field on a label: text, barcode or graphic, with everything that affects how it prints
+type ZPLElement struct {
+	Kind    string `json:"kind"` // "text", "barcode" or "graphic"
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Origin  string `json:"origin"` // FO or FT
+	Font    string `json:"font"`
+	Barcode string `json:"barcode"`
+	Module  string `json:"module"` // ^BY settings in effect for barcodes
+	Graphic string `json:"graphic"`
+	Block   string `json:"block"` // ^FB field block
+	Reverse bool   `json:"reverse"`
+	Data    string `json:"data"`
+}
+
+// ZPLLabel is one ^XA...^XZ block reduced to its label settings and fields
+type ZPLLabel struct {
+	Settings map[string]string `json:"settings"`
+	Elements []ZPLElement      `json:"elements"`
+}
+
+// ZPLChange is a single semantic difference between two ZPL streams
+type ZPLChange struct {
+	Label     int    `json:"label"`
+	Type      string `json:"type"` // "added", "removed", "changed" or "setting"
+	Element   string `json:"element"`
+	Attribute string `json:"attribute"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+}
+
+// ZPLDiff is the result of comparing two ZPL streams command by command
+type ZPLDiff struct {
+	Identical bool        `json:"identical"`
+	Changes   []ZPLChange `json:"changes"`
+}
+
+// zplLabelSettings are label-level commands compared as settings rather than fields
+var zplLabelSettings = map[string]bool{
+	"PW": true, "LL": true, "LH": true, "LS": true, "LT": true, "LR": true, "PO": true,
+	"PQ": true, "MD": true, "PR": true, "CI": true, "MN": true, "MT": true, "CF": true, "PM": true,
+}
+
+// BuildZPLLabels reduces a ZPL stream to its labels, ignoring whitespace, comments and the
+// order of commands within a field
+func BuildZPLLabels(zpl string) []ZPLLabel {
+	var labels []ZPLLabel
+	var label *ZPLLabel
+	charset := 0
+	defaultFont := ""
+	module := ""
+	var field ZPLElement
+	inField := false
+	hexIndicator := byte(0)
+
+	resetField := func() {
+		field = ZPLElement{}
+		inField = false
+		hexIndicator = 0
+	}
+	finishField := func() {
+		if label == nil || !inField {
+			resetField()
+			return
+		}
+		switch {
+		case field.Graphic != "":
+			field.Kind = "graphic"
+		case field.Barcode != "":
+			field.Kind = "barcode"
+			field.Module = module
+		default:
+			field.Kind = "text"
+			if field.Font == "" {
+				field.Font = defaultFont
+			}
+		}
+		if field.Kind == "graphic" || field.Kind == "barcode" {
+			field.Font = ""
+		}
+		label.Elements = append(label.Elements, field)
+		resetField()
+	}
+
+	for _, cmd := range ParseZPL(zpl) {
+		params := cmd.Params
+		if !zplTakesFieldData(cmd.Name) {
+			params = strings.TrimSpace(params)
+		}
+		switch {
+		case cmd.Name == "XA":
+			labels = append(labels, ZPLLabel{Settings: map[string]string{}})
+			label = &labels[len(labels)-1]
+			module = ""
+			resetField()
+		case cmd.Name == "XZ":
+			finishField()
+			label = nil
+		case label == nil:
+			continue
+		case cmd.Name == "CI":
+			charset = zplParamInt(zplParams(params), 0, 0)
+			label.Settings[cmd.Name] = params
+		case cmd.Name == "CF":
+			defaultFont = params
+			label.Settings[cmd.Name] = params
+		case zplLabelSettings[cmd.Name]:
+			label.Settings[cmd.Name] = params
+		case cmd.Name == "BY":
+			module = params
+		case cmd.Name == "FO" || cmd.Name == "FT":
+			p := zplParams(params)
+			field.X, field.Y = zplParamInt(p, 0, 0), zplParamInt(p, 1, 0)
+			field.Origin = cmd.Name
+			inField = true
+		case cmd.Name == "A":
+			field.Font = params
+		case cmd.Name == "FB":
+			field.Block = params
+		case cmd.Name == "FR":
+			field.Reverse = true
+		case cmd.Name == "FH":
+			hexIndicator = '_'
+			if params != "" {
+				hexIndicator = params[0]
+			}
+		case cmd.Name == "FD" || cmd.Name == "FV":
+			data := []byte(cmd.Params)
+			if hexIndicator != 0 {
+				data = decodeZPLHex(data, hexIndicator)
+			}
+			field.Data = decodeZPLText(data, charset)
+			inField = true
+		case cmd.Name == "FS":
+			finishField()
+		case strings.HasPrefix(cmd.Name, "B") && cmd.Prefix == '^':
+			field.Barcode = cmd.Name + ":" + params
+			inField = true
+		case strings.HasPrefix(cmd.Name, "G") && cmd.Prefix == '^':
+			field.Graphic = cmd.Name + ":" + params
+			inField = true
+		}
+	}
+	return labels
+}
+
+// DiffZPL compares two ZPL streams semantically and lists what changed on each label
+func DiffZPL(oldZPL string, newZPL string) ZPLDiff {
+	oldLabels, newLabels := BuildZPLLabels(oldZPL), BuildZPLLabels(newZPL)
+	var changes []ZPLChange
+	count := len(oldLabels)
+	if len(newLabels) > count {
+		count = len(newLabels)
+	}
+	for i := 0; i < count; i++ {
+		switch {
+		case i >= len(oldLabels):
+			changes = append(changes, ZPLChange{Label: i + 1, Type: "added", Element: "label"})
+		case i >= len(newLabels):
+			changes = append(changes, ZPLChange{Label: i + 1, Type: "removed", Element: "label"})
+		default:
+			changes = append(changes, diffZPLLabel(i+1, oldLabels[i], newLabels[i])...)
+		}
+	}
+	return ZPLDiff{Identical: len(changes) == 0, Changes: changes}
+}
+
+func diffZPLLabel(labelNumber int, oldLabel ZPLLabel, newLabel ZPLLabel) []ZPLChange {
+	var changes []ZPLChange
+
+	var keys []string
+	for k := range oldLabel.Settings {
+		keys = append(keys, k)
+	}
+	for k := range newLabel.Settings {
+		if _, ok := oldLabel.Settings[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		oldValue, newValue := oldLabel.Settings[k], newLabel.Settings[k]
+		if oldValue != newValue {
+			changes = append(changes, ZPLChange{Label: labelNumber, Type: "setting", Element: "^" + k, Attribute: k, Old: oldValue, New: newValue})
+		}
+	}
+
+	oldRemaining := append([]ZPLElement{}, oldLabel.Elements...)
+	newRemaining := append([]ZPLElement{}, newLabel.Elements...)
+
+	// Pair elements in passes from strongest to weakest match; anything left over was added or removed
+	passes := []func(a, b ZPLElement) bool{
+		func(a, b ZPLElement) bool { return a == b },
+		func(a, b ZPLElement) bool { return a.Kind == b.Kind && elementContent(a) == elementContent(b) },
+		func(a, b ZPLElement) bool { return a.Kind == b.Kind && a.X == b.X && a.Y == b.Y },
+	}
+	for _, match := range passes {
+		var unmatchedOld []ZPLElement
+		for _, o := range oldRemaining {
+			found := -1
+			for j, n := range newRemaining {
+				if match(o, n) {
+					found = j
+					break
+				}
+			}
+			if found < 0 {
+				unmatchedOld = append(unmatchedOld, o)
+				continue
+			}
+			changes = append(changes, elementChanges(labelNumber, o, newRemaining[found])...)
+			newRemaining = append(newRemaining[:found], newRemaining[found+1:]...)
+		}
+		oldRemaining = unmatchedOld
+	}
+
+	for _, o := range oldRemaining {
+		changes = append(changes, ZPLChange{Label: labelNumber, Type: "removed", Element: describeElement(o)})
+	}
+	for _, n := range newRemaining {
+		changes = append(changes, ZPLChange{Label: labelNumber, Type: "added", Element: describeElement(n)})
+	}
+	return changes
+}
+
+// elementContent is what identifies an element regardless of where it is printed
+func elementContent(e ZPLElement) string {
+	if e.Kind == "graphic" {
+		return e.Graphic
+	}
+	return e.Data
+}
+
+// elementChanges lists the attributes that differ between two matched elements
+func elementChanges(labelNumber int, o ZPLElement, n ZPLElement) []ZPLChange {
+	var changes []ZPLChange
+	add := func(attribute, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, ZPLChange{
+				Label: labelNumber, Type: "changed", Element: describeElement(o),
+				Attribute: attribute, Old: oldValue, New: newValue,
+			})
+		}
+	}
+	add("position", fmt.Sprintf("%s %d,%d", o.Origin, o.X, o.Y), fmt.Sprintf("%s %d,%d", n.Origin, n.X, n.Y))
+	add("font", o.Font, n.Font)
+	add("barcode", o.Barcode, n.Barcode)
+	add("barcode module", o.Module, n.Module)
+	add("graphic", shortenZPL(o.Graphic), shortenZPL(n.Graphic))
+	add("field block", o.Block, n.Block)
+	add("reverse", fmt.Sprint(o.Reverse), fmt.Sprint(n.Reverse))
+	add("data", o.Data, n.Data)
+	return changes
+}
+
+func describeElement(e ZPLElement) string {
+	switch e.Kind {
+	case "graphic":
+		return fmt.Sprintf("graphic %s at %d,%d", shortenZPL(e.Graphic), e.X, e.Y)
+	case "barcode":
+		return fmt.Sprintf("barcode %s %q at %d,%d", strings.SplitN(e.Barcode, ":", 2)[0], e.Data, e.X, e.Y)
+	default:
+		return fmt.Sprintf("text %q at %d,%d", e.Data, e.X, e.Y)
+	}
+}
+
+// shortenZPL keeps long parameters such as ^GF image data readable in a report
+func shortenZPL(s string) string {
+	if len(s) > 40 {
+		return s[:37] + "..."
+	}
+	return s
+}
+
+// FormatZPLDiff renders a diff as plain text, one change per line
+func FormatZPLDiff(d ZPLDiff) string {
+	if d.Identical {
+		return "No differences\n"
+	}
+	var b strings.Builder
+	for _, c := range d.Changes {
+		switch c.Type {
+		case "setting":
+			fmt.Fprintf(&b, "label %d: %s: %s -> %s\n", c.Label, c.Element, quoteEmpty(c.Old), quoteEmpty(c.New))
+		case "changed":
+			fmt.Fprintf(&b, "label %d: %s %s: %s -> %s\n", c.Label, c.Element, c.Attribute, quoteEmpty(c.Old), quoteEmpty(c.New))
+		default:
+			fmt.Fprintf(&b, "label %d: %s %s\n", c.Label, c.Type, c.Element)
+		}
+	}
+	return b.String()
+}
+
+func quoteEmpty(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// DiffJobs compares the ZPL of two stored jobs
+func (a *App) DiffJobs(oldJobID int, newJobID int) (*ZPLDiff, error) {
+	oldData, err := a.jobRawData(oldJobID)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := a.jobRawData(newJobID)
+	if err != nil {
+		return nil, err
+	}
+	diff := DiffZPL(string(oldData), string(newData))
+	return &diff, nil
+}